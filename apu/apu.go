@@ -0,0 +1,384 @@
+// Package apu emulates the GB's audio processing unit
+// There are 4 channels: 2 square waves (the first with a frequency sweep),
+// a wave channel playing samples from wave RAM, and a noise channel
+// Their outputs are mixed into stereo samples at the frontend's sample rate
+// Reference: http://gbdev.gg8.se/wiki/articles/Gameboy_sound_hardware
+package apu
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"../cartridge"
+)
+
+// GB can execute 4194304 (4.19MHz) cycles per second
+const cyclesPerSecond = 4194304
+
+// The frame sequencer steps at 512Hz, which is every 8192 cycles
+const frameSequencerCycles = cyclesPerSecond / 512
+
+// Each stereo sample is two signed 16-bit little endian values, left first
+const bytesPerSample = 4
+
+// Samples kept for the frontend before the oldest are dropped
+// Stops the buffer growing forever when nothing is playing the audio
+const maxBufferedSeconds = 1
+
+// GBAPU represents the sound registers and the state of each channel
+type GBAPU struct {
+	square1 squareChannel
+	square2 squareChannel
+	wave    waveChannel
+	noise   noiseChannel
+
+	// NR52 bit 7. While off, all registers except NR52 and wave RAM are
+	// cleared and ignore writes
+	powered bool
+	// Master volume, NR50
+	nr50 byte
+	// Channel panning, NR51
+	nr51 byte
+
+	// Next frame sequencer step, 0-7
+	frameStep int
+	// Cycles since the frame sequencer last stepped
+	frameCycles int
+
+	// Output samples per second
+	sampleRate int
+	// Sample rate * cycles since the last sample was generated
+	// Scaled so no rounding is needed when the rate doesn't divide the clock
+	sampleCycles int
+
+	// Generated samples waiting to be read by the frontend
+	// Read is called from the audio goroutine, so it's guarded by a mutex
+	bufferLock sync.Mutex
+	buffer     []byte
+}
+
+// InitAPU sets the sound registers to their post-boot values
+// model decides how wave RAM behaves while channel 3 plays, and sampleRate
+// is the number of stereo samples generated per second
+// Reference: http://bgb.bircd.org/pandocs.htm#powerupsequence
+func (gbapu *GBAPU) InitAPU(model cartridge.Model, sampleRate int) {
+	gbapu.wave.dmg = model != cartridge.CGB
+	gbapu.sampleRate = sampleRate
+	gbapu.sampleCycles = 0
+	gbapu.buffer = nil
+
+	gbapu.PowerOff()
+	gbapu.WriteRegister(0xFF26, 0x80)
+
+	gbapu.WriteRegister(0xFF10, 0x80)
+	gbapu.WriteRegister(0xFF11, 0xBF)
+	gbapu.WriteRegister(0xFF12, 0xF3)
+	gbapu.WriteRegister(0xFF13, 0xC1)
+	gbapu.WriteRegister(0xFF14, 0x07)
+	gbapu.WriteRegister(0xFF16, 0x3F)
+	gbapu.WriteRegister(0xFF1A, 0x7F)
+	gbapu.WriteRegister(0xFF1B, 0xFF)
+	gbapu.WriteRegister(0xFF1C, 0x9F)
+	gbapu.WriteRegister(0xFF20, 0xFF)
+	gbapu.WriteRegister(0xFF24, 0x77)
+	gbapu.WriteRegister(0xFF25, 0xF3)
+
+	// The boot ROM's sound has faded out, but channel 1 is still running
+	gbapu.square1.on = true
+}
+
+// PowerOff turns the APU off, which is its state at power on
+// The boot ROM turns it back on through NR52
+func (gbapu *GBAPU) PowerOff() {
+	// Length counters aren't affected by power on DMG, so they're kept
+	square1Length := gbapu.square1.length.counter
+	square2Length := gbapu.square2.length.counter
+	waveLength := gbapu.wave.length.counter
+	noiseLength := gbapu.noise.length.counter
+
+	gbapu.square1 = newSquareChannel(true)
+	gbapu.square2 = newSquareChannel(false)
+	gbapu.noise = newNoiseChannel()
+
+	// Wave RAM isn't cleared either
+	waveRAM := gbapu.wave.ram
+	dmg := gbapu.wave.dmg
+	gbapu.wave = newWaveChannel()
+	gbapu.wave.ram = waveRAM
+	gbapu.wave.dmg = dmg
+
+	gbapu.square1.length.counter = square1Length
+	gbapu.square2.length.counter = square2Length
+	gbapu.wave.length.counter = waveLength
+	gbapu.noise.length.counter = noiseLength
+
+	gbapu.nr50 = 0
+	gbapu.nr51 = 0
+	gbapu.powered = false
+}
+
+// lengthStepNext returns true if the frame sequencer's next step clocks the
+// length counters, which changes how NRx4 writes behave
+func (gbapu *GBAPU) lengthStepNext() bool {
+	return gbapu.frameStep%2 == 0
+}
+
+// ReadRegister returns the value of a sound register or wave RAM
+// addr must be in 0xFF10-0xFF3F
+// Write-only bits and unused registers read as 1
+func (gbapu *GBAPU) ReadRegister(addr uint16) byte {
+	switch {
+	case addr >= 0xFF30:
+		return gbapu.wave.readRAM(int(addr - 0xFF30))
+	case addr < 0xFF15:
+		return gbapu.square1.read(int(addr - 0xFF10))
+	case addr < 0xFF1A:
+		return gbapu.square2.read(int(addr - 0xFF15))
+	case addr < 0xFF1F:
+		return gbapu.wave.read(int(addr - 0xFF1A))
+	case addr < 0xFF24:
+		return gbapu.noise.read(int(addr - 0xFF1F))
+	case addr == 0xFF24:
+		return gbapu.nr50
+	case addr == 0xFF25:
+		return gbapu.nr51
+	case addr == 0xFF26:
+		return gbapu.readNR52()
+	}
+
+	return 0xFF
+}
+
+// readNR52 returns the power bit and which channels are on
+func (gbapu *GBAPU) readNR52() byte {
+	nr52 := byte(0x70)
+
+	if gbapu.powered {
+		nr52 |= 0x80
+	}
+	if gbapu.square1.on {
+		nr52 |= 0x01
+	}
+	if gbapu.square2.on {
+		nr52 |= 0x02
+	}
+	if gbapu.wave.on {
+		nr52 |= 0x04
+	}
+	if gbapu.noise.on {
+		nr52 |= 0x08
+	}
+
+	return nr52
+}
+
+// WriteRegister sets a sound register or wave RAM
+// addr must be in 0xFF10-0xFF3F
+func (gbapu *GBAPU) WriteRegister(addr uint16, data byte) {
+	if addr >= 0xFF30 {
+		gbapu.wave.writeRAM(int(addr-0xFF30), data)
+		return
+	}
+
+	if addr == 0xFF26 {
+		gbapu.writeNR52(data)
+		return
+	}
+
+	// While powered off, only the length counters can be written
+	if !gbapu.powered {
+		switch addr {
+		case 0xFF11:
+			gbapu.square1.length.load(int(data & 0x3F))
+		case 0xFF16:
+			gbapu.square2.length.load(int(data & 0x3F))
+		case 0xFF1B:
+			gbapu.wave.length.load(int(data))
+		case 0xFF20:
+			gbapu.noise.length.load(int(data & 0x3F))
+		}
+		return
+	}
+
+	lengthStepNext := gbapu.lengthStepNext()
+
+	switch {
+	case addr < 0xFF15:
+		gbapu.square1.write(int(addr-0xFF10), data, lengthStepNext)
+	case addr < 0xFF1A:
+		gbapu.square2.write(int(addr-0xFF15), data, lengthStepNext)
+	case addr < 0xFF1F:
+		gbapu.wave.write(int(addr-0xFF1A), data, lengthStepNext)
+	case addr < 0xFF24:
+		gbapu.noise.write(int(addr-0xFF1F), data, lengthStepNext)
+	case addr == 0xFF24:
+		gbapu.nr50 = data
+	case addr == 0xFF25:
+		gbapu.nr51 = data
+	}
+}
+
+// writeNR52 turns the APU on or off
+// The channel status bits are read only
+func (gbapu *GBAPU) writeNR52(data byte) {
+	if data&0x80 == 0 {
+		if gbapu.powered {
+			gbapu.PowerOff()
+		}
+		return
+	}
+
+	if gbapu.powered {
+		return
+	}
+
+	// Powering on restarts the frame sequencer at step 0
+	gbapu.powered = true
+	gbapu.frameStep = 0
+	gbapu.frameCycles = 0
+}
+
+// Update runs the APU for a number of cycles
+// The CPU calls this through its tick as each M-cycle of an instruction
+// happens, at normal speed even when the CPU is in double speed mode
+func (gbapu *GBAPU) Update(cycles int) {
+	if gbapu.powered {
+		gbapu.frameCycles += cycles
+		for gbapu.frameCycles >= frameSequencerCycles {
+			gbapu.frameCycles -= frameSequencerCycles
+			gbapu.stepFrameSequencer()
+		}
+
+		gbapu.square1.update(cycles)
+		gbapu.square2.update(cycles)
+		// The wave channel runs at half the CPU's clock, and Update is only
+		// ever called with whole M-cycles, so cycles is always even
+		gbapu.wave.update(cycles / 2)
+		gbapu.noise.update(cycles)
+	}
+
+	if gbapu.sampleRate == 0 {
+		return
+	}
+
+	gbapu.sampleCycles += cycles * gbapu.sampleRate
+	for gbapu.sampleCycles >= cyclesPerSecond {
+		gbapu.sampleCycles -= cyclesPerSecond
+		gbapu.writeSample(gbapu.mix())
+	}
+}
+
+// stepFrameSequencer clocks the length counters, sweep and envelopes
+//
+//	Step   Length  Sweep  Envelope
+//	0      Clock   -      -
+//	1      -       -      -
+//	2      Clock   Clock  -
+//	3      -       -      -
+//	4      Clock   -      -
+//	5      -       -      -
+//	6      Clock   Clock  -
+//	7      -       -      Clock
+func (gbapu *GBAPU) stepFrameSequencer() {
+	if gbapu.frameStep%2 == 0 {
+		gbapu.square1.clockLength()
+		gbapu.square2.clockLength()
+		gbapu.wave.clockLength()
+		gbapu.noise.clockLength()
+	}
+
+	if gbapu.frameStep == 2 || gbapu.frameStep == 6 {
+		gbapu.square1.clockSweep()
+	}
+
+	if gbapu.frameStep == 7 {
+		gbapu.square1.env.clock()
+		gbapu.square2.env.clock()
+		gbapu.noise.env.clock()
+	}
+
+	gbapu.frameStep = (gbapu.frameStep + 1) & 7
+}
+
+// mix combines the channel outputs into a left and right sample
+// NR51 picks which channels go to each side, and NR50 sets each side's volume
+func (gbapu *GBAPU) mix() (int16, int16) {
+	if !gbapu.powered {
+		return 0, 0
+	}
+
+	outputs := [4]byte{
+		gbapu.square1.output(),
+		gbapu.square2.output(),
+		gbapu.wave.output(),
+		gbapu.noise.output(),
+	}
+
+	var left, right int
+	for i, out := range outputs {
+		if gbapu.nr51&(1<<uint(i+4)) != 0 {
+			left += int(out)
+		}
+		if gbapu.nr51&(1<<uint(i)) != 0 {
+			right += int(out)
+		}
+	}
+
+	leftVolume := int(gbapu.nr50>>4&0x07) + 1
+	rightVolume := int(gbapu.nr50&0x07) + 1
+
+	// 4 channels at volume 15 and master volume 8 comes out to just under
+	// half of the int16 range, which leaves room for the frontend
+	return int16(left * leftVolume * 32), int16(right * rightVolume * 32)
+}
+
+// writeSample adds a stereo sample to the buffer read by the frontend
+func (gbapu *GBAPU) writeSample(left, right int16) {
+	var sample [bytesPerSample]byte
+	binary.LittleEndian.PutUint16(sample[0:], uint16(left))
+	binary.LittleEndian.PutUint16(sample[2:], uint16(right))
+
+	gbapu.bufferLock.Lock()
+	defer gbapu.bufferLock.Unlock()
+
+	gbapu.buffer = append(gbapu.buffer, sample[:]...)
+
+	maxBuffered := gbapu.sampleRate * bytesPerSample * maxBufferedSeconds
+	if len(gbapu.buffer) > maxBuffered {
+		gbapu.buffer = gbapu.buffer[len(gbapu.buffer)-maxBuffered:]
+	}
+}
+
+// Read fills p with generated samples, implementing io.Reader so the APU can
+// be handed to an audio player directly
+// Samples are stereo signed 16-bit little endian at the rate given to InitAPU
+// If nothing has been generated yet, silence is returned instead of blocking
+// so the player doesn't stall
+func (gbapu *GBAPU) Read(p []byte) (int, error) {
+	gbapu.bufferLock.Lock()
+	defer gbapu.bufferLock.Unlock()
+
+	// Only hand out whole samples
+	n := len(p) - len(p)%bytesPerSample
+
+	if len(gbapu.buffer) == 0 {
+		for i := range p[:n] {
+			p[i] = 0
+		}
+		return n, nil
+	}
+
+	if n > len(gbapu.buffer) {
+		n = len(gbapu.buffer)
+	}
+
+	copy(p, gbapu.buffer[:n])
+	gbapu.buffer = gbapu.buffer[n:]
+
+	return n, nil
+}
+
+// Close does nothing, it's only here so the APU satisfies io.ReadCloser
+func (gbapu *GBAPU) Close() error {
+	return nil
+}
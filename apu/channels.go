@@ -0,0 +1,104 @@
+package apu
+
+// lengthCounter disables a channel after a set amount of time
+// It's clocked at 256Hz by the frame sequencer when enabled in NRx4
+type lengthCounter struct {
+	enabled bool
+	counter int
+	// 64 for square and noise channels, 256 for the wave channel
+	max int
+}
+
+// load sets the counter from the length bits written to NRx1
+func (lc *lengthCounter) load(length int) {
+	lc.counter = lc.max - length
+}
+
+// clock decrements the counter if enabled
+// Returns true if the counter hit 0 and the channel should be disabled
+func (lc *lengthCounter) clock() bool {
+	if !lc.enabled || lc.counter == 0 {
+		return false
+	}
+
+	lc.counter--
+	return lc.counter == 0
+}
+
+// setEnabled handles the length enable bit written to NRx4
+// If the frame sequencer's next step doesn't clock length, enabling the
+// counter clocks it once immediately
+// Returns true if that extra clock hit 0 and the channel should be disabled
+func (lc *lengthCounter) setEnabled(enabled bool, lengthStepNext bool) bool {
+	wasEnabled := lc.enabled
+	lc.enabled = enabled
+
+	if !wasEnabled && enabled && !lengthStepNext && lc.counter > 0 {
+		lc.counter--
+		return lc.counter == 0
+	}
+
+	return false
+}
+
+// trigger reloads the counter if it reached 0
+// Same extra clock as setEnabled applies to the reloaded value
+func (lc *lengthCounter) trigger(lengthStepNext bool) {
+	if lc.counter != 0 {
+		return
+	}
+
+	lc.counter = lc.max
+	if lc.enabled && !lengthStepNext {
+		lc.counter--
+	}
+}
+
+// envelope changes a channel's volume over time
+// It's clocked at 64Hz by the frame sequencer
+type envelope struct {
+	// Raw NRx2 value
+	reg byte
+
+	volume byte
+	timer  byte
+}
+
+// dacEnabled returns true if NRx2's upper 5 bits are nonzero
+// With the DAC off, the channel is disabled
+func (env *envelope) dacEnabled() bool {
+	return env.reg&0xF8 != 0
+}
+
+// period returns the number of clocks between volume changes
+func (env *envelope) period() byte {
+	return env.reg & 0x07
+}
+
+// trigger resets the volume to the initial value in NRx2
+func (env *envelope) trigger() {
+	env.volume = env.reg >> 4
+	env.timer = env.period()
+}
+
+// clock moves the volume up or down once the timer runs out
+// A period of 0 disables the envelope
+func (env *envelope) clock() {
+	if env.period() == 0 {
+		return
+	}
+
+	if env.timer > 0 {
+		env.timer--
+	}
+
+	if env.timer == 0 {
+		env.timer = env.period()
+
+		if env.reg&0x08 != 0 && env.volume < 15 {
+			env.volume++
+		} else if env.reg&0x08 == 0 && env.volume > 0 {
+			env.volume--
+		}
+	}
+}
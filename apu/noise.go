@@ -0,0 +1,118 @@
+package apu
+
+// Divisors selected by the lower 3 bits of NR43
+var noiseDivisors = [8]int{8, 16, 32, 48, 64, 80, 96, 112}
+
+// noiseChannel outputs pseudo-random noise from a linear feedback shift register
+type noiseChannel struct {
+	on     bool
+	length lengthCounter
+	env    envelope
+
+	// Raw NR43 value
+	poly  byte
+	timer int
+	lfsr  uint16
+}
+
+func newNoiseChannel() noiseChannel {
+	return noiseChannel{
+		length: lengthCounter{max: 64},
+	}
+}
+
+// read returns the value of register NR40-NR44
+// NR40 doesn't exist, reg 0 is just there to line up with the other channels
+func (nc *noiseChannel) read(reg int) byte {
+	switch reg {
+	case 2:
+		return nc.env.reg
+	case 3:
+		return nc.poly
+	case 4:
+		if nc.length.enabled {
+			return 0xFF
+		}
+		return 0xBF
+	default:
+		return 0xFF
+	}
+}
+
+// write sets register NR40-NR44
+func (nc *noiseChannel) write(reg int, data byte, lengthStepNext bool) {
+	switch reg {
+	case 1:
+		nc.length.load(int(data & 0x3F))
+	case 2:
+		nc.env.reg = data
+		if !nc.env.dacEnabled() {
+			nc.on = false
+		}
+	case 3:
+		nc.poly = data
+	case 4:
+		if nc.length.setEnabled(data&0x40 != 0, lengthStepNext) && data&0x80 == 0 {
+			nc.on = false
+		}
+
+		if data&0x80 != 0 {
+			nc.trigger(lengthStepNext)
+		}
+	}
+}
+
+// period returns the number of cycles between LFSR shifts
+func (nc *noiseChannel) period() int {
+	return noiseDivisors[nc.poly&0x07] << (nc.poly >> 4)
+}
+
+// trigger restarts the channel with all LFSR bits set
+func (nc *noiseChannel) trigger(lengthStepNext bool) {
+	nc.on = nc.env.dacEnabled()
+	nc.length.trigger(lengthStepNext)
+	nc.env.trigger()
+	nc.timer = nc.period()
+	nc.lfsr = 0x7FFF
+}
+
+// clockLength is called at 256Hz by the frame sequencer
+func (nc *noiseChannel) clockLength() {
+	if nc.length.clock() {
+		nc.on = false
+	}
+}
+
+// update shifts the LFSR by the cycles the CPU ran
+// The XOR of the lowest 2 bits is shifted in at bit 14, and also at bit 6
+// when NR43 bit 3 selects the short 7-bit mode
+func (nc *noiseChannel) update(cycles int) {
+	if !nc.on {
+		return
+	}
+
+	// Shift clocks 14 and 15 stop the LFSR
+	if nc.poly>>4 >= 14 {
+		return
+	}
+
+	nc.timer -= cycles
+	for nc.timer <= 0 {
+		nc.timer += nc.period()
+
+		bit := (nc.lfsr ^ nc.lfsr>>1) & 1
+		nc.lfsr = nc.lfsr>>1 | bit<<14
+		if nc.poly&0x08 != 0 {
+			nc.lfsr = nc.lfsr&^(1<<6) | bit<<6
+		}
+	}
+}
+
+// output returns the channel's current digital output, 0-15
+func (nc *noiseChannel) output() byte {
+	if !nc.on || nc.lfsr&1 != 0 {
+		return 0
+	}
+
+	return nc.env.volume
+}
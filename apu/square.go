@@ -0,0 +1,210 @@
+package apu
+
+// Duty cycle waveforms, selected by the upper 2 bits of NRx1
+// 12.5%, 25%, 50% and 75%
+var dutyPatterns = [4][8]byte{
+	{0, 0, 0, 0, 0, 0, 0, 1},
+	{1, 0, 0, 0, 0, 0, 0, 1},
+	{1, 0, 0, 0, 0, 1, 1, 1},
+	{0, 1, 1, 1, 1, 1, 1, 0},
+}
+
+// squareChannel is one of the two square wave channels
+// Channel 1 also has a frequency sweep, channel 2 doesn't
+type squareChannel struct {
+	on     bool
+	length lengthCounter
+	env    envelope
+
+	duty    byte
+	dutyPos byte
+
+	// 11-bit frequency from NRx3 and NRx4
+	freq  uint16
+	timer int
+
+	// Frequency sweep, channel 1 only
+	hasSweep     bool
+	sweepReg     byte
+	sweepTimer   byte
+	sweepEnabled bool
+	shadowFreq   uint16
+	// Set once a sweep calculation has used negate mode since the last
+	// trigger. Clearing negate after that disables the channel
+	negateUsed bool
+}
+
+func newSquareChannel(hasSweep bool) squareChannel {
+	return squareChannel{
+		hasSweep: hasSweep,
+		length:   lengthCounter{max: 64},
+	}
+}
+
+// read returns the value of register NRx0-NRx4
+func (sq *squareChannel) read(reg int) byte {
+	switch reg {
+	case 0:
+		if sq.hasSweep {
+			return sq.sweepReg | 0x80
+		}
+		return 0xFF
+	case 1:
+		return sq.duty<<6 | 0x3F
+	case 2:
+		return sq.env.reg
+	case 3:
+		return 0xFF
+	default:
+		if sq.length.enabled {
+			return 0xFF
+		}
+		return 0xBF
+	}
+}
+
+// write sets register NRx0-NRx4
+func (sq *squareChannel) write(reg int, data byte, lengthStepNext bool) {
+	switch reg {
+	case 0:
+		if !sq.hasSweep {
+			return
+		}
+
+		sq.sweepReg = data & 0x7F
+		if sq.negateUsed && data&0x08 == 0 {
+			sq.on = false
+		}
+	case 1:
+		sq.duty = data >> 6
+		sq.length.load(int(data & 0x3F))
+	case 2:
+		sq.env.reg = data
+		if !sq.env.dacEnabled() {
+			sq.on = false
+		}
+	case 3:
+		sq.freq = sq.freq&0x700 | uint16(data)
+	case 4:
+		sq.freq = sq.freq&0xFF | uint16(data&0x07)<<8
+
+		if sq.length.setEnabled(data&0x40 != 0, lengthStepNext) && data&0x80 == 0 {
+			sq.on = false
+		}
+
+		if data&0x80 != 0 {
+			sq.trigger(lengthStepNext)
+		}
+	}
+}
+
+// period returns the number of cycles between duty steps
+func (sq *squareChannel) period() int {
+	return (2048 - int(sq.freq)) * 4
+}
+
+// trigger restarts the channel, which happens when bit 7 of NRx4 is written
+func (sq *squareChannel) trigger(lengthStepNext bool) {
+	sq.on = sq.env.dacEnabled()
+	sq.length.trigger(lengthStepNext)
+	sq.env.trigger()
+	sq.timer = sq.period()
+
+	if sq.hasSweep {
+		sq.shadowFreq = sq.freq
+		sq.sweepTimer = sq.sweepPeriod()
+		sq.sweepEnabled = sq.sweepReg&0x77 != 0
+		sq.negateUsed = false
+
+		// Overflow check happens immediately if there's a shift
+		if sq.sweepShift() != 0 {
+			sq.sweepCalc()
+		}
+	}
+}
+
+// sweepPeriod returns the sweep timer's reload value
+// A period of 0 is treated as 8
+func (sq *squareChannel) sweepPeriod() byte {
+	period := (sq.sweepReg >> 4) & 0x07
+	if period == 0 {
+		return 8
+	}
+
+	return period
+}
+
+// sweepShift returns the shift applied to the frequency each sweep
+func (sq *squareChannel) sweepShift() byte {
+	return sq.sweepReg & 0x07
+}
+
+// sweepCalc returns the next frequency, disabling the channel if it
+// overflows past 11 bits
+func (sq *squareChannel) sweepCalc() uint16 {
+	delta := sq.shadowFreq >> sq.sweepShift()
+
+	var newFreq uint16
+	if sq.sweepReg&0x08 != 0 {
+		newFreq = sq.shadowFreq - delta
+		sq.negateUsed = true
+	} else {
+		newFreq = sq.shadowFreq + delta
+	}
+
+	if newFreq > 2047 {
+		sq.on = false
+	}
+
+	return newFreq
+}
+
+// clockSweep is called at 128Hz by the frame sequencer
+func (sq *squareChannel) clockSweep() {
+	if sq.sweepTimer > 0 {
+		sq.sweepTimer--
+	}
+
+	if sq.sweepTimer != 0 {
+		return
+	}
+
+	sq.sweepTimer = sq.sweepPeriod()
+	if !sq.sweepEnabled || (sq.sweepReg>>4)&0x07 == 0 {
+		return
+	}
+
+	newFreq := sq.sweepCalc()
+	if newFreq <= 2047 && sq.sweepShift() != 0 {
+		sq.shadowFreq = newFreq
+		sq.freq = newFreq
+
+		// The new frequency is checked for overflow again, but not used
+		sq.sweepCalc()
+	}
+}
+
+// clockLength is called at 256Hz by the frame sequencer
+func (sq *squareChannel) clockLength() {
+	if sq.length.clock() {
+		sq.on = false
+	}
+}
+
+// update advances the duty position by the cycles the CPU ran
+func (sq *squareChannel) update(cycles int) {
+	sq.timer -= cycles
+	for sq.timer <= 0 {
+		sq.timer += sq.period()
+		sq.dutyPos = (sq.dutyPos + 1) & 7
+	}
+}
+
+// output returns the channel's current digital output, 0-15
+func (sq *squareChannel) output() byte {
+	if !sq.on {
+		return 0
+	}
+
+	return dutyPatterns[sq.duty][sq.dutyPos] * sq.env.volume
+}
@@ -0,0 +1,182 @@
+package apu
+
+// waveChannel plays back 32 4-bit samples stored in wave RAM at 0xFF30-0xFF3F
+type waveChannel struct {
+	on     bool
+	length lengthCounter
+
+	// NR30 bit 7
+	dacOn bool
+	// NR32 bits 5-6: 0 is mute, 1 is 100%, 2 is 50%, 3 is 25%
+	volumeCode byte
+
+	// 11-bit frequency from NR33 and NR34
+	freq uint16
+	// APU cycles (2 CPU cycles each) left before the next sample is read
+	// The sample is read on the cycle after this reaches 0
+	countdown int
+	// Set for the APU cycle a sample was read on, the only time a DMG's
+	// CPU can access wave RAM while the channel plays
+	justRead bool
+
+	ram [16]byte
+	// Index of the current sample, 0-31, high nibble of each byte first
+	position byte
+	// Last sample read from wave RAM
+	sample byte
+
+	// Wave RAM access while playing and retriggering behave differently on
+	// DMG, see readRAM, writeRAM and trigger
+	dmg bool
+}
+
+func newWaveChannel() waveChannel {
+	return waveChannel{
+		length: lengthCounter{max: 256},
+	}
+}
+
+// read returns the value of register NR30-NR34
+func (wc *waveChannel) read(reg int) byte {
+	switch reg {
+	case 0:
+		if wc.dacOn {
+			return 0xFF
+		}
+		return 0x7F
+	case 2:
+		return wc.volumeCode<<5 | 0x9F
+	case 4:
+		if wc.length.enabled {
+			return 0xFF
+		}
+		return 0xBF
+	default:
+		return 0xFF
+	}
+}
+
+// write sets register NR30-NR34
+func (wc *waveChannel) write(reg int, data byte, lengthStepNext bool) {
+	switch reg {
+	case 0:
+		wc.dacOn = data&0x80 != 0
+		if !wc.dacOn {
+			wc.on = false
+		}
+	case 1:
+		wc.length.load(int(data))
+	case 2:
+		wc.volumeCode = (data >> 5) & 0x03
+	case 3:
+		wc.freq = wc.freq&0x700 | uint16(data)
+	case 4:
+		wc.freq = wc.freq&0xFF | uint16(data&0x07)<<8
+
+		if wc.length.setEnabled(data&0x40 != 0, lengthStepNext) && data&0x80 == 0 {
+			wc.on = false
+		}
+
+		if data&0x80 != 0 {
+			wc.trigger(lengthStepNext)
+		}
+	}
+}
+
+// readRAM returns a byte of wave RAM
+// While the channel is playing, the CPU only sees the byte being played
+// On DMG that's only within the cycle the channel reads it, other reads
+// return 0xFF
+func (wc *waveChannel) readRAM(index int) byte {
+	if wc.on {
+		if wc.dmg && !wc.justRead {
+			return 0xFF
+		}
+		index = int(wc.position / 2)
+	}
+
+	return wc.ram[index]
+}
+
+// writeRAM sets a byte of wave RAM
+// While the channel is playing, the write goes to the byte being played
+// On DMG that's only within the cycle the channel reads it, other writes
+// are ignored
+func (wc *waveChannel) writeRAM(index int, data byte) {
+	if wc.on {
+		if wc.dmg && !wc.justRead {
+			return
+		}
+		index = int(wc.position / 2)
+	}
+
+	wc.ram[index] = data
+}
+
+// period returns the number of APU cycles between samples
+func (wc *waveChannel) period() int {
+	return 2048 - int(wc.freq)
+}
+
+// trigger restarts playback from the first sample
+// The sample buffer isn't refilled, so the last sample played is heard again
+// The first sample is read 3 APU cycles later than a full period
+func (wc *waveChannel) trigger(lengthStepNext bool) {
+	// Retriggering a DMG just as the channel reads a sample corrupts the
+	// start of wave RAM with the bytes around the one being read
+	if wc.dmg && wc.on && wc.countdown == 0 {
+		index := int((wc.position+1)/2) & 0x0F
+		if index < 4 {
+			wc.ram[0] = wc.ram[index]
+		} else {
+			aligned := index &^ 3
+			copy(wc.ram[:4], wc.ram[aligned:aligned+4])
+		}
+	}
+
+	wc.on = wc.dacOn
+	wc.length.trigger(lengthStepNext)
+	wc.position = 0
+	wc.countdown = wc.period() + 2
+	wc.justRead = false
+}
+
+// clockLength is called at 256Hz by the frame sequencer
+func (wc *waveChannel) clockLength() {
+	if wc.length.clock() {
+		wc.on = false
+	}
+}
+
+// update advances the sample position by a number of APU cycles
+func (wc *waveChannel) update(apuCycles int) {
+	if !wc.on {
+		return
+	}
+
+	for i := 0; i < apuCycles; i++ {
+		wc.justRead = wc.countdown == 0
+		if !wc.justRead {
+			wc.countdown--
+			continue
+		}
+
+		wc.countdown = wc.period() - 1
+		wc.position = (wc.position + 1) & 31
+
+		wc.sample = wc.ram[wc.position/2]
+		if wc.position&1 == 0 {
+			wc.sample >>= 4
+		}
+		wc.sample &= 0x0F
+	}
+}
+
+// output returns the channel's current digital output, 0-15
+func (wc *waveChannel) output() byte {
+	if !wc.on || wc.volumeCode == 0 {
+		return 0
+	}
+
+	return wc.sample >> (wc.volumeCode - 1)
+}
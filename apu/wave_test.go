@@ -0,0 +1,92 @@
+package apu
+
+import (
+	"testing"
+)
+
+// newPlayingWave returns a DMG or CGB wave channel just triggered with a 4
+// APU cycle period, and wave RAM holding 0x00, 0x11, 0x22 and so on
+func newPlayingWave(dmg bool) *waveChannel {
+	wc := newWaveChannel()
+	wc.dmg = dmg
+	for i := range wc.ram {
+		wc.ram[i] = byte(i * 0x11)
+	}
+
+	wc.write(0, 0x80, false)
+	wc.write(3, 0xFC, false)
+	wc.write(4, 0x87, false)
+
+	return &wc
+}
+
+func TestWaveRAMWhilePlaying(t *testing.T) {
+	dmg := newPlayingWave(true)
+	cgb := newPlayingWave(false)
+
+	// The first sample is read 3 cycles after a full period, and the next
+	// one every 4 cycles after that
+	for cycle := 1; cycle <= 15; cycle++ {
+		dmg.update(1)
+		cgb.update(1)
+
+		reading := cycle == 7 || cycle == 11 || cycle == 15
+		expected := byte(0xFF)
+		if reading {
+			expected = dmg.ram[dmg.position/2]
+		}
+		if got := dmg.readRAM(0); got != expected {
+			t.Errorf("DMG read $%02X on cycle %d, expected $%02X", got, cycle, expected)
+		}
+		if got, expected := cgb.readRAM(0), cgb.ram[cgb.position/2]; got != expected {
+			t.Errorf("CGB read $%02X on cycle %d, expected $%02X", got, cycle, expected)
+		}
+	}
+
+	// Writes only land within the cycle a sample is read, in the byte being
+	// read rather than the one addressed
+	dmg.update(1)
+	dmg.writeRAM(0x0F, 0xAB)
+	dmg.update(3)
+	dmg.writeRAM(0x0F, 0xCD)
+	if dmg.ram[0x0F] != 0xFF || dmg.ram[2] != 0xCD {
+		t.Errorf("wave RAM is %X after writes while playing, expected only byte 2 changed to $CD", dmg.ram)
+	}
+}
+
+func TestWaveRetrigger(t *testing.T) {
+	tests := []struct {
+		name string
+		// Samples read before retriggering
+		samples int
+		// Expected first 4 bytes of wave RAM
+		expected [4]byte
+	}{
+		{"about to read byte 1", 1, [4]byte{0x11, 0x11, 0x22, 0x33}},
+		{"about to read byte 9", 17, [4]byte{0x88, 0x99, 0xAA, 0xBB}},
+	}
+
+	for _, test := range tests {
+		wc := newPlayingWave(true)
+		wc.update(6 + 4*test.samples)
+		if wc.countdown != 0 {
+			t.Fatalf("%s: countdown is %d, expected 0", test.name, wc.countdown)
+		}
+
+		wc.write(4, 0x87, false)
+
+		var got [4]byte
+		copy(got[:], wc.ram[:4])
+		if got != test.expected {
+			t.Errorf("%s: wave RAM starts with %X, expected %X", test.name, got, test.expected)
+		}
+	}
+
+	// Retriggering at any other time leaves wave RAM alone
+	wc := newPlayingWave(true)
+	wc.update(8)
+	wc.write(4, 0x87, false)
+	if wc.ram[0] != 0x00 {
+		t.Errorf("wave RAM byte 0 is $%02X after retriggering between reads, expected $00", wc.ram[0])
+	}
+}
@@ -0,0 +1,224 @@
+// Package cartridge parses and validates the header found in every Game Boy
+// cartridge ROM at 0x0100-0x014F
+// The header tells us which memory bank controller the cartridge uses, how
+// much ROM and RAM it has, and which hardware it was made for
+// Reference: http://gbdev.gg8.se/wiki/articles/The_Cartridge_Header
+package cartridge
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// NintendoLogo is the bitmap stored at 0x0104-0x0133 of every cartridge
+// The boot ROM refuses to start a game if it doesn't match
+var NintendoLogo = [48]byte{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83,
+	0x00, 0x0C, 0x00, 0x0D, 0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E,
+	0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99, 0xBB, 0xBB, 0x67, 0x63,
+	0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+// Header end, everything before this is required to parse a cartridge
+const headerEnd = 0x0150
+
+// Model is the Game Boy hardware a cartridge runs on
+type Model int
+
+// Supported models
+const (
+	DMG Model = iota // Original Game Boy
+	CGB              // Game Boy Color
+)
+
+// String returns the model's name
+func (model Model) String() string {
+	if model == CGB {
+		return "CGB"
+	}
+
+	return "DMG"
+}
+
+// Header holds the parsed contents of a cartridge header
+type Header struct {
+	// Upper case ASCII, padded with zeroes in the ROM
+	Title string
+	// 4 character manufacturer code, only in newer cartridges
+	Manufacturer string
+	// 0x80 if the game supports CGB functions, 0xC0 if it only works on CGB
+	CGBFlag byte
+	// 0x03 if the game supports SGB functions
+	SGBFlag byte
+	// Two character code for the publisher
+	// Older cartridges use a single byte, which is formatted as hex
+	Licensee    string
+	Type        Type
+	ROMSize     int
+	RAMSize     int
+	Destination byte
+	Version     byte
+
+	HeaderChecksum byte
+	GlobalChecksum uint16
+}
+
+// ROM sizes, indexed by header byte 0x0148
+// 0x00-0x08 are 32KB shifted left by the value
+var romSizes = map[byte]int{
+	0x52: 72 * 0x4000,
+	0x53: 80 * 0x4000,
+	0x54: 96 * 0x4000,
+}
+
+// RAM sizes, indexed by header byte 0x0149
+var ramSizes = map[byte]int{
+	0x00: 0,
+	0x01: 0x800,
+	0x02: 0x2000,
+	0x03: 0x8000,
+	0x04: 0x20000,
+	0x05: 0x10000,
+}
+
+// Parse reads the header of a cartridge ROM
+// Returns ErrTooSmall if rom can't contain a header, and a *HeaderError if
+// a field has a value we can't interpret
+// Checksums and the logo are not checked here, see Verify
+func Parse(rom []byte) (*Header, error) {
+	if len(rom) < headerEnd {
+		return nil, ErrTooSmall
+	}
+
+	header := &Header{
+		CGBFlag:        rom[0x0143],
+		SGBFlag:        rom[0x0146],
+		Type:           Type(rom[0x0147]),
+		Destination:    rom[0x014A],
+		Version:        rom[0x014C],
+		HeaderChecksum: rom[0x014D],
+		GlobalChecksum: uint16(rom[0x014E])<<8 | uint16(rom[0x014F]),
+	}
+
+	// The title used to be 16 characters, but CGB cartridges took over the
+	// last byte for the CGB flag and the 4 before that for a manufacturer
+	if header.CGBFlag&0x80 != 0 {
+		header.Title = parseString(rom[0x0134:0x013F])
+		header.Manufacturer = parseString(rom[0x013F:0x0143])
+	} else {
+		header.Title = parseString(rom[0x0134:0x0144])
+	}
+
+	// 0x33 means the licensee is in the newer 2 character field instead
+	if rom[0x014B] == 0x33 {
+		header.Licensee = parseString(rom[0x0144:0x0146])
+	} else {
+		header.Licensee = fmt.Sprintf("%02X", rom[0x014B])
+	}
+
+	if !header.Type.known() {
+		return nil, &HeaderError{Field: "cartridge type", Value: rom[0x0147]}
+	}
+
+	sizeCode := rom[0x0148]
+	if sizeCode <= 0x08 {
+		header.ROMSize = 0x8000 << sizeCode
+	} else if size, ok := romSizes[sizeCode]; ok {
+		header.ROMSize = size
+	} else {
+		return nil, &HeaderError{Field: "ROM size", Value: sizeCode}
+	}
+
+	ramSize, ok := ramSizes[rom[0x0149]]
+	if !ok {
+		return nil, &HeaderError{Field: "RAM size", Value: rom[0x0149]}
+	}
+	header.RAMSize = ramSize
+
+	return header, nil
+}
+
+// Model returns the hardware the cartridge should be run on
+// Cartridges which also work on the original Game Boy run as DMG, since
+// that's the hardware halken emulates
+func (header *Header) Model() Model {
+	if header.CGBFlag == 0xC0 {
+		return CGB
+	}
+
+	return DMG
+}
+
+// Verify checks the Nintendo logo, both checksums and the ROM size of a
+// cartridge against its header
+// Returns a *LogoError, *ChecksumError or *SizeError for each problem found
+// Real hardware only checks the logo and the header checksum, so plenty
+// of homebrew runs fine with a bad global checksum
+func (header *Header) Verify(rom []byte) []error {
+	var errs []error
+
+	if !bytes.Equal(rom[0x0104:0x0134], NintendoLogo[:]) {
+		errs = append(errs, &LogoError{})
+	}
+
+	if sum := HeaderChecksum(rom); sum != header.HeaderChecksum {
+		errs = append(errs, &ChecksumError{
+			Checksum: "header",
+			Expected: uint16(header.HeaderChecksum),
+			Actual:   uint16(sum),
+		})
+	}
+
+	if sum := GlobalChecksum(rom); sum != header.GlobalChecksum {
+		errs = append(errs, &ChecksumError{
+			Checksum: "global",
+			Expected: header.GlobalChecksum,
+			Actual:   sum,
+		})
+	}
+
+	if len(rom) != header.ROMSize {
+		errs = append(errs, &SizeError{Expected: header.ROMSize, Actual: len(rom)})
+	}
+
+	return errs
+}
+
+// HeaderChecksum computes the checksum of header bytes 0x0134-0x014C
+func HeaderChecksum(rom []byte) byte {
+	var sum byte
+	for _, b := range rom[0x0134:0x014D] {
+		sum = sum - b - 1
+	}
+
+	return sum
+}
+
+// GlobalChecksum computes the sum of every byte in the ROM except for the
+// global checksum itself
+func GlobalChecksum(rom []byte) uint16 {
+	var sum uint16
+	for i, b := range rom {
+		if i != 0x014E && i != 0x014F {
+			sum += uint16(b)
+		}
+	}
+
+	return sum
+}
+
+// parseString returns the printable ASCII in a header field up to the
+// first zero byte
+func parseString(field []byte) string {
+	if end := bytes.IndexByte(field, 0); end >= 0 {
+		field = field[:end]
+	}
+
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return -1
+		}
+		return r
+	}, string(field)))
+}
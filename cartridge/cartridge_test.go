@@ -0,0 +1,177 @@
+package cartridge
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testROM returns a 64KB MBC1 cartridge with a valid header, after letting
+// mutate change it. The checksums are fixed up after mutate unless it asks
+// for them to be kept by returning false
+func testROM(mutate func(rom []byte) bool) []byte {
+	rom := make([]byte, 0x10000)
+	copy(rom[0x0104:], NintendoLogo[:])
+	copy(rom[0x0134:], "TEST")
+	rom[0x0147] = 0x01 // MBC1
+	rom[0x0148] = 0x01 // 64KB
+	rom[0x0149] = 0x00 // No RAM
+	for i := range rom[headerEnd:] {
+		rom[headerEnd+i] = byte(i)
+	}
+
+	fixChecksums := true
+	if mutate != nil {
+		fixChecksums = mutate(rom)
+	}
+
+	if fixChecksums {
+		rom[0x014D] = HeaderChecksum(rom)
+		sum := GlobalChecksum(rom)
+		rom[0x014E] = byte(sum >> 8)
+		rom[0x014F] = byte(sum)
+	}
+
+	return rom
+}
+
+// describeError returns an error's type, along with the field that tells
+// errors of the same type apart
+func describeError(err error) string {
+	if err == nil {
+		return "no error"
+	}
+	if err == ErrTooSmall {
+		return "ErrTooSmall"
+	}
+
+	switch err := err.(type) {
+	case *HeaderError:
+		return fmt.Sprintf("%T for the %s", err, err.Field)
+	case *ChecksumError:
+		return fmt.Sprintf("%T for the %s checksum", err, err.Checksum)
+	default:
+		return fmt.Sprintf("%T", err)
+	}
+}
+
+func TestParseAndVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		rom  []byte
+		// Error returned by Parse, or the only one returned by Verify if
+		// Parse succeeds
+		expected error
+	}{
+		{
+			name: "valid",
+			rom:  testROM(nil),
+		},
+		{
+			name:     "truncated",
+			rom:      testROM(nil)[:0x014F],
+			expected: ErrTooSmall,
+		},
+		{
+			name: "bad header checksum",
+			rom: testROM(func(rom []byte) bool {
+				rom[0x014D] = HeaderChecksum(rom) + 1
+				sum := GlobalChecksum(rom)
+				rom[0x014E] = byte(sum >> 8)
+				rom[0x014F] = byte(sum)
+				return false
+			}),
+			expected: &ChecksumError{Checksum: "header"},
+		},
+		{
+			name: "bad global checksum",
+			rom: testROM(func(rom []byte) bool {
+				rom[0x014D] = HeaderChecksum(rom)
+				rom[0x014E] = 0x12
+				rom[0x014F] = 0x34
+				return false
+			}),
+			expected: &ChecksumError{Checksum: "global"},
+		},
+		{
+			name: "bad logo",
+			rom: testROM(func(rom []byte) bool {
+				rom[0x0110] ^= 0xFF
+				return true
+			}),
+			expected: &LogoError{},
+		},
+		{
+			name: "ROM smaller than header says",
+			rom: testROM(func(rom []byte) bool {
+				rom[0x0148] = 0x02
+				return true
+			}),
+			expected: &SizeError{},
+		},
+		{
+			name: "unknown cartridge type",
+			rom: testROM(func(rom []byte) bool {
+				rom[0x0147] = 0x40
+				return true
+			}),
+			expected: &HeaderError{Field: "cartridge type"},
+		},
+		{
+			name: "unknown ROM size",
+			rom: testROM(func(rom []byte) bool {
+				rom[0x0148] = 0x20
+				return true
+			}),
+			expected: &HeaderError{Field: "ROM size"},
+		},
+		{
+			name: "unknown RAM size",
+			rom: testROM(func(rom []byte) bool {
+				rom[0x0149] = 0x07
+				return true
+			}),
+			expected: &HeaderError{Field: "RAM size"},
+		},
+	}
+
+	for _, test := range tests {
+		header, err := Parse(test.rom)
+		if err == nil {
+			errs := header.Verify(test.rom)
+			if len(errs) > 1 {
+				t.Errorf("%s: Verify returned %d errors %v, expected at most 1", test.name, len(errs), errs)
+				continue
+			}
+			if len(errs) == 1 {
+				err = errs[0]
+			}
+		}
+
+		if got, expected := describeError(err), describeError(test.expected); got != expected {
+			t.Errorf("%s: got %s, expected %s", test.name, got, expected)
+		}
+	}
+}
+
+func TestChecksumErrorValues(t *testing.T) {
+	rom := testROM(nil)
+	rom[0x014D]++
+
+	header, err := Parse(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := header.Verify(rom)
+	if len(errs) == 0 {
+		t.Fatalf("Verify returned no errors")
+	}
+
+	checksumErr, ok := errs[0].(*ChecksumError)
+	if !ok {
+		t.Fatalf("got %T, expected *ChecksumError", errs[0])
+	}
+	if checksumErr.Expected != uint16(rom[0x014D]) || checksumErr.Actual != uint16(HeaderChecksum(rom)) {
+		t.Errorf("checksum error has expected %02X and actual %02X, expected %02X and %02X", checksumErr.Expected, checksumErr.Actual, rom[0x014D], HeaderChecksum(rom))
+	}
+}
@@ -0,0 +1,49 @@
+package cartridge
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTooSmall is returned when a ROM is too small to contain a header
+var ErrTooSmall = errors.New("cartridge: ROM is too small to contain a header")
+
+// HeaderError reports a header field with a value we can't interpret
+type HeaderError struct {
+	Field string
+	Value byte
+}
+
+func (err *HeaderError) Error() string {
+	return fmt.Sprintf("cartridge: unknown %s %02X", err.Field, err.Value)
+}
+
+// ChecksumError reports a checksum which doesn't match the ROM's contents
+// Checksum is either "header" or "global"
+type ChecksumError struct {
+	Checksum string
+	Expected uint16
+	Actual   uint16
+}
+
+func (err *ChecksumError) Error() string {
+	return fmt.Sprintf("cartridge: %s checksum is %04X, expected %04X", err.Checksum, err.Actual, err.Expected)
+}
+
+// LogoError reports a Nintendo logo which doesn't match the one in the boot
+// ROM. Real hardware locks up when this happens
+type LogoError struct{}
+
+func (err *LogoError) Error() string {
+	return "cartridge: Nintendo logo does not match"
+}
+
+// SizeError reports a ROM whose size doesn't match the header
+type SizeError struct {
+	Expected int
+	Actual   int
+}
+
+func (err *SizeError) Error() string {
+	return fmt.Sprintf("cartridge: ROM is %d bytes, header says %d", err.Actual, err.Expected)
+}
@@ -0,0 +1,107 @@
+package cartridge
+
+// Type is the cartridge type at header byte 0x0147
+// It identifies the memory bank controller and any extra hardware
+type Type byte
+
+// MBC identifies a memory bank controller
+type MBC int
+
+// Memory bank controllers
+const (
+	NoMBC MBC = iota
+	MBC1
+	MBC2
+	MBC3
+	MBC5
+	MBC6
+	MBC7
+	MMM01
+	PocketCamera
+	TAMA5
+	HuC1
+	HuC3
+)
+
+// typeInfo describes the hardware in a cartridge type
+type typeInfo struct {
+	name    string
+	mbc     MBC
+	ram     bool
+	battery bool
+	timer   bool
+	rumble  bool
+}
+
+// Every cartridge type listed in the header documentation
+var types = map[Type]typeInfo{
+	0x00: {"ROM ONLY", NoMBC, false, false, false, false},
+	0x01: {"MBC1", MBC1, false, false, false, false},
+	0x02: {"MBC1+RAM", MBC1, true, false, false, false},
+	0x03: {"MBC1+RAM+BATTERY", MBC1, true, true, false, false},
+	0x05: {"MBC2", MBC2, false, false, false, false},
+	0x06: {"MBC2+BATTERY", MBC2, false, true, false, false},
+	0x08: {"ROM+RAM", NoMBC, true, false, false, false},
+	0x09: {"ROM+RAM+BATTERY", NoMBC, true, true, false, false},
+	0x0B: {"MMM01", MMM01, false, false, false, false},
+	0x0C: {"MMM01+RAM", MMM01, true, false, false, false},
+	0x0D: {"MMM01+RAM+BATTERY", MMM01, true, true, false, false},
+	0x0F: {"MBC3+TIMER+BATTERY", MBC3, false, true, true, false},
+	0x10: {"MBC3+TIMER+RAM+BATTERY", MBC3, true, true, true, false},
+	0x11: {"MBC3", MBC3, false, false, false, false},
+	0x12: {"MBC3+RAM", MBC3, true, false, false, false},
+	0x13: {"MBC3+RAM+BATTERY", MBC3, true, true, false, false},
+	0x19: {"MBC5", MBC5, false, false, false, false},
+	0x1A: {"MBC5+RAM", MBC5, true, false, false, false},
+	0x1B: {"MBC5+RAM+BATTERY", MBC5, true, true, false, false},
+	0x1C: {"MBC5+RUMBLE", MBC5, false, false, false, true},
+	0x1D: {"MBC5+RUMBLE+RAM", MBC5, true, false, false, true},
+	0x1E: {"MBC5+RUMBLE+RAM+BATTERY", MBC5, true, true, false, true},
+	0x20: {"MBC6", MBC6, true, true, false, false},
+	0x22: {"MBC7+SENSOR+RUMBLE+RAM+BATTERY", MBC7, true, true, false, true},
+	0xFC: {"POCKET CAMERA", PocketCamera, true, true, false, false},
+	0xFD: {"BANDAI TAMA5", TAMA5, true, true, true, false},
+	0xFE: {"HuC3", HuC3, true, true, true, false},
+	0xFF: {"HuC1+RAM+BATTERY", HuC1, true, true, false, false},
+}
+
+// known returns true if the type is one we have a description for
+func (cartType Type) known() bool {
+	_, ok := types[cartType]
+	return ok
+}
+
+// String returns the name of the cartridge type, e.g. MBC1+RAM+BATTERY
+func (cartType Type) String() string {
+	if info, ok := types[cartType]; ok {
+		return info.name
+	}
+
+	return "UNKNOWN"
+}
+
+// MBC returns the memory bank controller used by the cartridge type
+func (cartType Type) MBC() MBC {
+	return types[cartType].mbc
+}
+
+// HasRAM returns true if the cartridge has external RAM
+// MBC2 has RAM built into the controller, which isn't counted here
+func (cartType Type) HasRAM() bool {
+	return types[cartType].ram
+}
+
+// HasBattery returns true if the cartridge RAM or clock is battery backed
+func (cartType Type) HasBattery() bool {
+	return types[cartType].battery
+}
+
+// HasTimer returns true if the cartridge has a real-time clock
+func (cartType Type) HasTimer() bool {
+	return types[cartType].timer
+}
+
+// HasRumble returns true if the cartridge has a rumble motor
+func (cartType Type) HasRumble() bool {
+	return types[cartType].rumble
+}
@@ -4,52 +4,282 @@
 // instructions contains all CPU instructions
 package cpu
 
+import (
+	"../cartridge"
+	"../interrupts"
+)
+
+// Number of cycles the CPU is paused for while switching speed
+// Reference: http://bgb.bircd.org/pandocs.htm#cgbregisters
+const speedSwitchCycles = 2050 * 4
+
+// Tick injection from main.go
+// Runs the rest of the system (LCD, timer, etc.) for a number of cycles
+// The CPU calls this as each memory access happens rather than once the
+// instruction is done, so other components see reads and writes on the
+// same cycle hardware does
+var Tick func(cycles int)
+
+// Bus is the memory the CPU reads and writes
+type Bus interface {
+	ReadData(addr uint16) byte
+	WriteData(addr uint16, data byte)
+}
+
 // GBCPU represents an instance of an LR35902
 // Reference: http://www.zilog.com/docs/z80/um0080.pdf
 // Page 80 discusses clocks
 type GBCPU struct {
 	// Total time cycles
-	TCycles  uint16
-	Regs     *Registers
-	Instrs   map[byte]Instruction
-	InstrsCB map[byte]Instruction
-	Jumped   bool
+	TCycles uint16
+	Regs    *Registers
+	Jumped  bool
 	// Interrupt master enabled flag
 	// Not accessed by a mem address and not technically(?) a register
 	// Accessed directly by the CPU
 	IME        byte
 	EIReceived bool
 	Halted     bool
-	// Interrupt flag prior to halting
-	IFPreHalt byte
+	// Set by HALT when it triggers the HALT bug, see HALT
+	haltBug bool
+	// Set by STOP until a selected joypad line goes low
+	Stopped bool
+	// CGB double speed mode, switched by STOP
+	// The CPU and timer run twice as fast relative to the LCD and sound
+	DoubleSpeed bool
+	// Set by an illegal opcode, see lockUp
+	Locked bool
+	// Why the CPU locked up, nil while it's running
+	Fault *Fault
+	// Called once when the CPU locks up, if set
+	OnFault func(fault *Fault)
+	// Logs executed instructions while set and enabled, see trace.go
+	Tracer *Tracer
+
+	// Recently executed instructions, see fault.go
+	history    [historySize]Executed
+	historyPos int
+
+	// Cycles already ticked during the current instruction
+	ticked int
+
+	// Functions that execute each instruction, indexed by opcode
+	// See dispatch.go
+	executors   [256]func() int
+	executorsCB [256]func()
 }
 
 // InitCPU initializes a new CPU struct
-// Sets Regs and Instrs fields
+// Sets Regs and executor tables
 // Sets program counter to location
-func (gbcpu *GBCPU) InitCPU() {
+// Register values depend on which model the cartridge is run as
+func (gbcpu *GBCPU) InitCPU(model cartridge.Model) {
 	gbcpu.IME = 0
 	gbcpu.Halted = false
+	gbcpu.haltBug = false
+	gbcpu.Stopped = false
+	gbcpu.DoubleSpeed = false
+	gbcpu.Locked = false
+	gbcpu.Fault = nil
+	gbcpu.historyPos = 0
 	gbcpu.EIReceived = false
 	gbcpu.Regs = new(Registers)
-	gbcpu.Regs.InitRegs()
+	gbcpu.Regs.InitRegs(model)
 	// For now, start PC at usual jump destination after
 	// cartridge header information
-	gbcpu.Regs.PC = append(gbcpu.Regs.PC, 0x00, 0x01)
-	gbcpu.loadInstructions()
+	gbcpu.Regs.pc = 0x0100
+	gbcpu.loadExecutors()
 }
 
 // pushByteToStack decrements the SP by 1, then writes a byte at the addr
 // pointed to by the SP
 func (gbcpu *GBCPU) pushByteToStack(data byte) {
-	gbcpu.Regs.decrementSP(1)
-	GbMMU.WriteData(gbcpu.sliceToInt(gbcpu.Regs.sp), data)
+	gbcpu.Regs.sp--
+	gbcpu.write(gbcpu.Regs.sp, data)
 }
 
 // popByteFromStack gets the byte at the addr pointed to by the SP
 // then increments the SP by 1
 func (gbcpu *GBCPU) popByteFromStack() byte {
-	result := GbMMU.ReadData(gbcpu.sliceToInt(gbcpu.Regs.sp))
-	gbcpu.Regs.incrementSP(1)
+	result := gbcpu.read(gbcpu.Regs.sp)
+	gbcpu.Regs.sp++
 	return result
 }
+
+// pushWordToStack pushes the upper byte of data, then the lower byte
+func (gbcpu *GBCPU) pushWordToStack(data uint16) {
+	gbcpu.pushByteToStack(byte(data >> 8))
+	gbcpu.pushByteToStack(byte(data))
+}
+
+// popWordFromStack pops the lower byte, then the upper byte
+func (gbcpu *GBCPU) popWordFromStack() uint16 {
+	low := gbcpu.popByteFromStack()
+	high := gbcpu.popByteFromStack()
+	return uint16(high)<<8 | uint16(low)
+}
+
+// modifyHL reads the byte at addr (HL), passes it to op, then writes the
+// result back through the MMU
+// Used by read-modify-write instructions like RLC (HL) and SET 0,(HL)
+func (gbcpu *GBCPU) modifyHL(op func(*byte)) {
+	addr := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
+	val := gbcpu.read(addr)
+	op(&val)
+	gbcpu.write(addr, val)
+}
+
+// Step services a pending interrupt if there is one, otherwise it fetches
+// and executes the instruction at PC, then moves PC past it unless the
+// instruction jumped
+// While halted, Step idles for an M-cycle instead, until an enabled
+// interrupt is requested. This wakes the CPU even if IME is 0, in which
+// case it continues after the HALT without servicing the interrupt
+// While stopped, the system clock isn't running at all, so nothing is
+// ticked until a button in a column selected through P1 pulls its line low
+// Once locked up by an illegal opcode, Step only idles for an M-cycle
+// Returns the number of cycles taken. All of them have been ticked by the
+// time Step returns
+func (gbcpu *GBCPU) Step() int {
+	if gbcpu.Locked {
+		gbcpu.ticked = 0
+		gbcpu.tick(4)
+		return 4
+	}
+
+	if gbcpu.Stopped {
+		// Only the selected joypad lines wake the CPU, so P1 is read
+		// like the game would. This isn't a CPU access, so it isn't ticked
+		if GbBus.ReadData(0xFF00)&0x0F == 0x0F {
+			return 4
+		}
+
+		gbcpu.Stopped = false
+	}
+
+	if gbcpu.Halted {
+		if GbInterrupts.Pending() == 0 {
+			gbcpu.ticked = 0
+			gbcpu.tick(4)
+			return 4
+		}
+
+		gbcpu.Halted = false
+	}
+
+	cycles := gbcpu.HandleInterrupts()
+	if cycles != 0 {
+		return cycles
+	}
+
+	// EI enables interrupts only after the instruction following it, so
+	// EI followed by DI never lets an interrupt through
+	enableIME := gbcpu.EIReceived
+
+	gbcpu.ticked = 0
+	gbcpu.Jumped = false
+
+	pc := gbcpu.Regs.pc
+	if gbcpu.Tracer != nil && gbcpu.Tracer.Enabled {
+		gbcpu.Tracer.trace(gbcpu, pc)
+	}
+	opcode := gbcpu.read(pc)
+
+	// The HALT bug makes the CPU act as if PC was never incremented past
+	// the opcode, so its operands start at the opcode itself and a 1 byte
+	// instruction runs twice
+	if gbcpu.haltBug {
+		gbcpu.haltBug = false
+		gbcpu.Regs.pc = pc - 1
+	}
+
+	// Illegal opcodes have no executor
+	if gbcpu.executors[opcode] == nil {
+		gbcpu.lockUp(pc, opcode)
+		return 4
+	}
+	gbcpu.recordHistory(pc, opcode)
+
+	cycles = gbcpu.Execute(opcode)
+	gbcpu.finishCycles(cycles)
+
+	if !gbcpu.Jumped {
+		gbcpu.Regs.pc += Instructions[opcode].NumOperands
+	}
+
+	if enableIME && gbcpu.EIReceived {
+		gbcpu.IME = 1
+		gbcpu.EIReceived = false
+	}
+
+	return cycles
+}
+
+// HandleInterrupts jumps to the handler of the highest priority pending
+// interrupt, if IME is set
+// Dispatch takes 20 cycles: 2 internal M-cycles, pushing PC, then setting PC
+// to the handler. If pushing the upper byte of PC overwrites IE at 0xFFFF,
+// the interrupt is picked again after the push. When nothing is pending
+// anymore, dispatch is cancelled and PC is set to 0x0000 instead
+// Returns the number of cycles taken, or 0 if nothing was dispatched
+func (gbcpu *GBCPU) HandleInterrupts() int {
+	if gbcpu.IME == 0 || GbInterrupts.Pending() == 0 {
+		return 0
+	}
+
+	gbcpu.ticked = 0
+	gbcpu.IME = 0
+	gbcpu.EIReceived = false
+
+	// EI followed by HALT with an interrupt pending hits the HALT bug, but
+	// the interrupt is dispatched before the next opcode is fetched. The
+	// return address is the HALT itself, so it runs again after RETI
+	if gbcpu.haltBug {
+		gbcpu.haltBug = false
+		gbcpu.Regs.pc--
+	}
+
+	gbcpu.tick(8)
+	gbcpu.pushByteToStack(byte(gbcpu.Regs.pc >> 8))
+	source, ok := GbInterrupts.Next()
+	gbcpu.pushByteToStack(byte(gbcpu.Regs.pc))
+
+	handler := uint16(0x0000)
+	if ok {
+		GbInterrupts.Acknowledge(source)
+		handler = interrupts.Vector(source)
+	}
+
+	gbcpu.Regs.pc = handler
+	gbcpu.finishCycles(20)
+
+	return 20
+}
+
+// finishCycles ticks whatever is left of an instruction's cycles after its
+// memory accesses, which are internal cycles like the ALU in ADD HL,BC
+func (gbcpu *GBCPU) finishCycles(cycles int) {
+	if gbcpu.ticked < cycles {
+		gbcpu.tick(cycles - gbcpu.ticked)
+	}
+}
+
+// tick runs the rest of the system for cycles
+func (gbcpu *GBCPU) tick(cycles int) {
+	gbcpu.ticked += cycles
+	if Tick != nil {
+		Tick(cycles)
+	}
+}
+
+// read returns the byte at addr after ticking the memory access's M-cycle
+func (gbcpu *GBCPU) read(addr uint16) byte {
+	gbcpu.tick(4)
+	return GbBus.ReadData(addr)
+}
+
+// write sets the byte at addr after ticking the memory access's M-cycle
+func (gbcpu *GBCPU) write(addr uint16, data byte) {
+	gbcpu.tick(4)
+	GbBus.WriteData(addr, data)
+}
@@ -0,0 +1,191 @@
+package cpu
+
+import (
+	"testing"
+
+	"../cartridge"
+	"../interrupts"
+	"../mmu"
+)
+
+// testBus is 64KB of RAM, so the CPU can run without the rest of the system
+type testBus [65536]byte
+
+func (bus *testBus) ReadData(addr uint16) byte {
+	return bus[addr]
+}
+
+func (bus *testBus) WriteData(addr uint16, data byte) {
+	bus[addr] = data
+}
+
+// newTestCPU returns a CPU about to run program from 0x0100
+// Nothing is ticked, and no interrupts are enabled or requested
+func newTestCPU(program []byte) (*GBCPU, *testBus) {
+	bus := new(testBus)
+	copy(bus[0x0100:], program)
+
+	GbBus = bus
+	GbInterrupts = new(interrupts.GBInterrupts)
+	Tick = nil
+
+	gbcpu := new(GBCPU)
+	gbcpu.InitCPU(cartridge.DMG)
+
+	return gbcpu, bus
+}
+
+// HALT with IME=0 and an interrupt pending doesn't halt, and the byte after
+// it is read twice because PC fails to increment
+func TestHaltBug(t *testing.T) {
+	gbcpu, _ := newTestCPU([]byte{
+		0x76, // 0100: HALT
+		0x3C, // 0101: INC A
+		0x00, // 0102: NOP
+	})
+	gbcpu.Regs.SetA(0x00)
+	GbInterrupts.WriteIE(1 << interrupts.Timer)
+	GbInterrupts.WriteIF(1 << interrupts.Timer)
+
+	expected := []uint16{0x0101, 0x0101, 0x0102, 0x0103}
+	for i, pc := range expected {
+		gbcpu.Step()
+		if gbcpu.Halted {
+			t.Fatalf("CPU halted on step %d with an interrupt pending", i+1)
+		}
+		if gbcpu.Regs.PC() != pc {
+			t.Fatalf("PC is $%04X after step %d, expected $%04X", gbcpu.Regs.PC(), i+1, pc)
+		}
+	}
+
+	if gbcpu.Regs.A() != 0x02 {
+		t.Errorf("A is $%02X, expected INC A to run twice", gbcpu.Regs.A())
+	}
+	if GbInterrupts.ReadIF()&(1<<interrupts.Timer) == 0 {
+		t.Errorf("timer interrupt serviced with IME=0")
+	}
+}
+
+// EI then HALT with an interrupt pending triggers the HALT bug, but EI's
+// delay has set IME by the next Step, so the interrupt is dispatched first
+// The handler must run normally, and return to the HALT
+func TestHaltBugAfterEI(t *testing.T) {
+	gbcpu, bus := newTestCPU([]byte{
+		0xFB, // 0100: EI
+		0x76, // 0101: HALT
+	})
+	copy(bus[0x0040:], []byte{0xC3, 0x00, 0x20}) // 0040: JP $2000
+	gbcpu.Regs.SetSP(0xD000)
+	GbInterrupts.WriteIE(1 << interrupts.VBlank)
+	GbInterrupts.WriteIF(1 << interrupts.VBlank)
+
+	expected := []uint16{0x0101, 0x0102, 0x0040, 0x2000}
+	for i, pc := range expected {
+		gbcpu.Step()
+		if gbcpu.Regs.PC() != pc {
+			t.Fatalf("PC is $%04X after step %d, expected $%04X", gbcpu.Regs.PC(), i+1, pc)
+		}
+	}
+
+	returnAddr := uint16(bus[0xCFFF])<<8 | uint16(bus[0xCFFE])
+	if returnAddr != 0x0101 {
+		t.Errorf("pushed return address $%04X, expected $0101", returnAddr)
+	}
+}
+
+// STOP only wakes when a joypad line selected through P1 reads low, and
+// nothing is ticked while stopped
+func TestStopWake(t *testing.T) {
+	gbcpu, bus := newTestCPU([]byte{
+		0x10, 0x00, // 0100: STOP
+		0x3C, // 0102: INC A
+	})
+	GbMMU = new(mmu.GBMMU)
+	mmu.ResetDivider = func() {}
+	gbcpu.Regs.SetA(0x00)
+
+	ticked := 0
+	Tick = func(cycles int) {
+		ticked += cycles
+	}
+
+	// A button in a column that isn't selected leaves every line high
+	bus[0xFF00] = 0x0F
+	gbcpu.Step()
+	if !gbcpu.Stopped {
+		t.Fatalf("CPU didn't stop")
+	}
+
+	ticked = 0
+	for i := 0; i < 3; i++ {
+		gbcpu.Step()
+	}
+	if !gbcpu.Stopped || gbcpu.Regs.PC() != 0x0102 {
+		t.Errorf("CPU woke with every joypad line high, PC is $%04X", gbcpu.Regs.PC())
+	}
+	if ticked != 0 {
+		t.Errorf("%d cycles ticked while stopped", ticked)
+	}
+
+	bus[0xFF00] = 0x0B
+	gbcpu.Step()
+	if gbcpu.Stopped || gbcpu.Regs.A() != 0x01 {
+		t.Errorf("CPU didn't wake and run INC A with a joypad line low")
+	}
+}
+
+// An illegal opcode locks the CPU up for good, reporting the fault once
+func TestIllegalOpcode(t *testing.T) {
+	gbcpu, _ := newTestCPU([]byte{
+		0x00,       // 0100: NOP
+		0x3E, 0x12, // 0101: LD A,$12
+		0x3C, // 0103: INC A
+		0xD3, // 0104: illegal
+		0x3C, // 0105: INC A
+	})
+
+	var faults []*Fault
+	gbcpu.OnFault = func(fault *Fault) {
+		faults = append(faults, fault)
+	}
+
+	for i := 0; i < 4; i++ {
+		gbcpu.Step()
+	}
+	if !gbcpu.Locked {
+		t.Fatalf("CPU didn't lock up on $D3")
+	}
+
+	// Interrupts don't get it going again either
+	gbcpu.IME = 1
+	GbInterrupts.WriteIE(1 << interrupts.VBlank)
+	GbInterrupts.WriteIF(1 << interrupts.VBlank)
+	for i := 0; i < 10; i++ {
+		if cycles := gbcpu.Step(); cycles != 4 {
+			t.Errorf("locked Step took %d cycles, expected 4", cycles)
+		}
+	}
+
+	if gbcpu.Regs.PC() != 0x0104 || gbcpu.Regs.A() != 0x13 {
+		t.Errorf("PC is $%04X and A is $%02X, expected the CPU to stay at $0104 with A $13", gbcpu.Regs.PC(), gbcpu.Regs.A())
+	}
+	if len(faults) != 1 {
+		t.Fatalf("OnFault called %d times, expected once", len(faults))
+	}
+
+	fault := faults[0]
+	if fault != gbcpu.Fault || fault.PC != 0x0104 || fault.Opcode != 0xD3 {
+		t.Errorf("fault is %v, expected illegal opcode $D3 at $0104", fault)
+	}
+
+	expected := []Executed{{0x0100, 0x00}, {0x0101, 0x3E}, {0x0103, 0x3C}}
+	if len(fault.History) != len(expected) {
+		t.Fatalf("fault history is %v, expected %v", fault.History, expected)
+	}
+	for i := range expected {
+		if fault.History[i] != expected[i] {
+			t.Errorf("fault history is %v, expected %v", fault.History, expected)
+			break
+		}
+	}
+}
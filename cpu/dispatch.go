@@ -0,0 +1,527 @@
+package cpu
+
+// Instructions are dispatched by indexing fixed size tables with the opcode,
+// rather than looking them up in a map
+// The decode information (mnemonic, cycles, operands) doesn't depend on the
+// CPU's state, so it lives in the package level tables in instructions.go
+// The executors close over a GBCPU, so each one builds its own tables here
+
+// Execute runs the non-CB prefixed instruction for opcode
+// Returns the number of cycles the instruction took, which includes any
+// extra cycles for conditional instructions that completed
+func (gbcpu *GBCPU) Execute(opcode byte) int {
+	return int(Instructions[opcode].TCycles) + gbcpu.executors[opcode]()
+}
+
+// loadExecutors fills in the executor tables, indexed by opcode
+// Non-CB executors return the extra cycles taken by conditional instructions
+// that completed, like RET Z when the zero flag is set, or 0 otherwise
+// Opcodes with no instruction are left nil, Step locks up the CPU on them
+func (gbcpu *GBCPU) loadExecutors() {
+	gbcpu.executors = [256]func() int{
+		0x00: func() int { return 0 },
+		0x01: func() int { gbcpu.LDrrnn(&gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0x02: func() int { gbcpu.LDaar(&gbcpu.Regs.b, &gbcpu.Regs.c, &gbcpu.Regs.a); return 0 },
+		0x03: func() int { gbcpu.INCrr(&gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0x04: func() int { gbcpu.INCr(&gbcpu.Regs.b); return 0 },
+		0x05: func() int { gbcpu.DECr(&gbcpu.Regs.b); return 0 },
+		0x06: func() int { gbcpu.LDrn(&gbcpu.Regs.b); return 0 },
+		0x07: func() int { gbcpu.RLCA(); return 0 },
+		0x08: func() int { gbcpu.LDaaSP(); return 0 },
+		0x09: func() int { gbcpu.ADDHLrr(&gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0x0A: func() int { gbcpu.LDraa(&gbcpu.Regs.a, &gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0x0B: func() int { gbcpu.DECrr(&gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0x0C: func() int { gbcpu.INCr(&gbcpu.Regs.c); return 0 },
+		0x0D: func() int { gbcpu.DECr(&gbcpu.Regs.c); return 0 },
+		0x0E: func() int { gbcpu.LDrn(&gbcpu.Regs.c); return 0 },
+		0x0F: func() int { gbcpu.RRCA(); return 0 },
+		0x10: func() int { return gbcpu.STOP() },
+		0x11: func() int { gbcpu.LDrrnn(&gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0x12: func() int { gbcpu.LDaar(&gbcpu.Regs.d, &gbcpu.Regs.e, &gbcpu.Regs.a); return 0 },
+		0x13: func() int { gbcpu.INCrr(&gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0x14: func() int { gbcpu.INCr(&gbcpu.Regs.d); return 0 },
+		0x15: func() int { gbcpu.DECr(&gbcpu.Regs.d); return 0 },
+		0x16: func() int { gbcpu.LDrn(&gbcpu.Regs.d); return 0 },
+		0x17: func() int { gbcpu.RLA(); return 0 },
+		0x18: func() int { gbcpu.JRn(); return 0 },
+		0x19: func() int { gbcpu.ADDHLrr(&gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0x1A: func() int { gbcpu.LDraa(&gbcpu.Regs.a, &gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0x1B: func() int { gbcpu.DECrr(&gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0x1C: func() int { gbcpu.INCr(&gbcpu.Regs.e); return 0 },
+		0x1D: func() int { gbcpu.DECr(&gbcpu.Regs.e); return 0 },
+		0x1E: func() int { gbcpu.LDrn(&gbcpu.Regs.e); return 0 },
+		0x1F: func() int { gbcpu.RRA(); return 0 },
+		0x20: func() int { return gbcpu.JRNZn() },
+		0x21: func() int { gbcpu.LDrrnn(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x22: func() int { gbcpu.LDIHLA(); return 0 },
+		0x23: func() int { gbcpu.INCrr(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x24: func() int { gbcpu.INCr(&gbcpu.Regs.h); return 0 },
+		0x25: func() int { gbcpu.DECr(&gbcpu.Regs.h); return 0 },
+		0x26: func() int { gbcpu.LDrn(&gbcpu.Regs.h); return 0 },
+		0x27: func() int { gbcpu.DAA(); return 0 },
+		0x28: func() int { return gbcpu.JRZn() },
+		0x29: func() int { gbcpu.ADDHLrr(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x2A: func() int { gbcpu.LDIAHL(); return 0 },
+		0x2B: func() int { gbcpu.DECrr(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x2C: func() int { gbcpu.INCr(&gbcpu.Regs.l); return 0 },
+		0x2D: func() int { gbcpu.DECr(&gbcpu.Regs.l); return 0 },
+		0x2E: func() int { gbcpu.LDrn(&gbcpu.Regs.l); return 0 },
+		0x2F: func() int { gbcpu.CPL(); return 0 },
+		0x30: func() int { return gbcpu.JRNCn() },
+		0x31: func() int { gbcpu.LDSPnn(); return 0 },
+		0x32: func() int { gbcpu.LDDHLr(&gbcpu.Regs.a); return 0 },
+		0x33: func() int { gbcpu.INCSP(); return 0 },
+		0x34: func() int { gbcpu.INCHL(); return 0 },
+		0x35: func() int { gbcpu.DECHL(); return 0 },
+		0x36: func() int { gbcpu.LDHLn(); return 0 },
+		0x37: func() int { gbcpu.SCF(); return 0 },
+		0x38: func() int { return gbcpu.JRCn() },
+		0x39: func() int { gbcpu.ADDHLSP(); return 0 },
+		0x3A: func() int { gbcpu.LDDrHL(&gbcpu.Regs.a); return 0 },
+		0x3B: func() int { gbcpu.DECSP(); return 0 },
+		0x3C: func() int { gbcpu.INCr(&gbcpu.Regs.a); return 0 },
+		0x3D: func() int { gbcpu.DECr(&gbcpu.Regs.a); return 0 },
+		0x3E: func() int { gbcpu.LDrn(&gbcpu.Regs.a); return 0 },
+		0x3F: func() int { gbcpu.CCF(); return 0 },
+		0x40: func() int { gbcpu.LDrr(&gbcpu.Regs.b, &gbcpu.Regs.b); return 0 },
+		0x41: func() int { gbcpu.LDrr(&gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0x42: func() int { gbcpu.LDrr(&gbcpu.Regs.b, &gbcpu.Regs.d); return 0 },
+		0x43: func() int { gbcpu.LDrr(&gbcpu.Regs.b, &gbcpu.Regs.e); return 0 },
+		0x44: func() int { gbcpu.LDrr(&gbcpu.Regs.b, &gbcpu.Regs.h); return 0 },
+		0x45: func() int { gbcpu.LDrr(&gbcpu.Regs.b, &gbcpu.Regs.l); return 0 },
+		0x46: func() int { gbcpu.LDraa(&gbcpu.Regs.b, &gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x47: func() int { gbcpu.LDrr(&gbcpu.Regs.b, &gbcpu.Regs.a); return 0 },
+		0x48: func() int { gbcpu.LDrr(&gbcpu.Regs.c, &gbcpu.Regs.b); return 0 },
+		0x49: func() int { gbcpu.LDrr(&gbcpu.Regs.c, &gbcpu.Regs.c); return 0 },
+		0x4A: func() int { gbcpu.LDrr(&gbcpu.Regs.c, &gbcpu.Regs.d); return 0 },
+		0x4B: func() int { gbcpu.LDrr(&gbcpu.Regs.c, &gbcpu.Regs.e); return 0 },
+		0x4C: func() int { gbcpu.LDrr(&gbcpu.Regs.c, &gbcpu.Regs.h); return 0 },
+		0x4D: func() int { gbcpu.LDrr(&gbcpu.Regs.c, &gbcpu.Regs.l); return 0 },
+		0x4E: func() int { gbcpu.LDraa(&gbcpu.Regs.c, &gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x4F: func() int { gbcpu.LDrr(&gbcpu.Regs.c, &gbcpu.Regs.a); return 0 },
+		0x50: func() int { gbcpu.LDrr(&gbcpu.Regs.d, &gbcpu.Regs.b); return 0 },
+		0x51: func() int { gbcpu.LDrr(&gbcpu.Regs.d, &gbcpu.Regs.c); return 0 },
+		0x52: func() int { gbcpu.LDrr(&gbcpu.Regs.d, &gbcpu.Regs.d); return 0 },
+		0x53: func() int { gbcpu.LDrr(&gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0x54: func() int { gbcpu.LDrr(&gbcpu.Regs.d, &gbcpu.Regs.h); return 0 },
+		0x55: func() int { gbcpu.LDrr(&gbcpu.Regs.d, &gbcpu.Regs.l); return 0 },
+		0x56: func() int { gbcpu.LDraa(&gbcpu.Regs.d, &gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x57: func() int { gbcpu.LDrr(&gbcpu.Regs.d, &gbcpu.Regs.a); return 0 },
+		0x58: func() int { gbcpu.LDrr(&gbcpu.Regs.e, &gbcpu.Regs.b); return 0 },
+		0x59: func() int { gbcpu.LDrr(&gbcpu.Regs.e, &gbcpu.Regs.c); return 0 },
+		0x5A: func() int { gbcpu.LDrr(&gbcpu.Regs.e, &gbcpu.Regs.d); return 0 },
+		0x5B: func() int { gbcpu.LDrr(&gbcpu.Regs.e, &gbcpu.Regs.e); return 0 },
+		0x5C: func() int { gbcpu.LDrr(&gbcpu.Regs.e, &gbcpu.Regs.h); return 0 },
+		0x5D: func() int { gbcpu.LDrr(&gbcpu.Regs.e, &gbcpu.Regs.l); return 0 },
+		0x5E: func() int { gbcpu.LDraa(&gbcpu.Regs.e, &gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x5F: func() int { gbcpu.LDrr(&gbcpu.Regs.e, &gbcpu.Regs.a); return 0 },
+		0x60: func() int { gbcpu.LDrr(&gbcpu.Regs.h, &gbcpu.Regs.b); return 0 },
+		0x61: func() int { gbcpu.LDrr(&gbcpu.Regs.h, &gbcpu.Regs.c); return 0 },
+		0x62: func() int { gbcpu.LDrr(&gbcpu.Regs.h, &gbcpu.Regs.d); return 0 },
+		0x63: func() int { gbcpu.LDrr(&gbcpu.Regs.h, &gbcpu.Regs.e); return 0 },
+		0x64: func() int { gbcpu.LDrr(&gbcpu.Regs.h, &gbcpu.Regs.h); return 0 },
+		0x65: func() int { gbcpu.LDrr(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x66: func() int { gbcpu.LDraa(&gbcpu.Regs.h, &gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x67: func() int { gbcpu.LDrr(&gbcpu.Regs.h, &gbcpu.Regs.a); return 0 },
+		0x68: func() int { gbcpu.LDrr(&gbcpu.Regs.l, &gbcpu.Regs.b); return 0 },
+		0x69: func() int { gbcpu.LDrr(&gbcpu.Regs.l, &gbcpu.Regs.c); return 0 },
+		0x6A: func() int { gbcpu.LDrr(&gbcpu.Regs.l, &gbcpu.Regs.d); return 0 },
+		0x6B: func() int { gbcpu.LDrr(&gbcpu.Regs.l, &gbcpu.Regs.e); return 0 },
+		0x6C: func() int { gbcpu.LDrr(&gbcpu.Regs.l, &gbcpu.Regs.h); return 0 },
+		0x6D: func() int { gbcpu.LDrr(&gbcpu.Regs.l, &gbcpu.Regs.l); return 0 },
+		0x6E: func() int { gbcpu.LDraa(&gbcpu.Regs.l, &gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x6F: func() int { gbcpu.LDrr(&gbcpu.Regs.l, &gbcpu.Regs.a); return 0 },
+		0x70: func() int { gbcpu.LDaar(&gbcpu.Regs.h, &gbcpu.Regs.l, &gbcpu.Regs.b); return 0 },
+		0x71: func() int { gbcpu.LDaar(&gbcpu.Regs.h, &gbcpu.Regs.l, &gbcpu.Regs.c); return 0 },
+		0x72: func() int { gbcpu.LDaar(&gbcpu.Regs.h, &gbcpu.Regs.l, &gbcpu.Regs.d); return 0 },
+		0x73: func() int { gbcpu.LDaar(&gbcpu.Regs.h, &gbcpu.Regs.l, &gbcpu.Regs.e); return 0 },
+		0x74: func() int { gbcpu.LDaar(&gbcpu.Regs.h, &gbcpu.Regs.l, &gbcpu.Regs.h); return 0 },
+		0x75: func() int { gbcpu.LDaar(&gbcpu.Regs.h, &gbcpu.Regs.l, &gbcpu.Regs.l); return 0 },
+		0x76: func() int { gbcpu.HALT(); return 0 },
+		0x77: func() int { gbcpu.LDaar(&gbcpu.Regs.h, &gbcpu.Regs.l, &gbcpu.Regs.a); return 0 },
+		0x78: func() int { gbcpu.LDrr(&gbcpu.Regs.a, &gbcpu.Regs.b); return 0 },
+		0x79: func() int { gbcpu.LDrr(&gbcpu.Regs.a, &gbcpu.Regs.c); return 0 },
+		0x7A: func() int { gbcpu.LDrr(&gbcpu.Regs.a, &gbcpu.Regs.d); return 0 },
+		0x7B: func() int { gbcpu.LDrr(&gbcpu.Regs.a, &gbcpu.Regs.e); return 0 },
+		0x7C: func() int { gbcpu.LDrr(&gbcpu.Regs.a, &gbcpu.Regs.h); return 0 },
+		0x7D: func() int { gbcpu.LDrr(&gbcpu.Regs.a, &gbcpu.Regs.l); return 0 },
+		0x7E: func() int { gbcpu.LDraa(&gbcpu.Regs.a, &gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0x7F: func() int { gbcpu.LDrr(&gbcpu.Regs.a, &gbcpu.Regs.a); return 0 },
+		0x80: func() int { gbcpu.ADDrr(&gbcpu.Regs.a, &gbcpu.Regs.b); return 0 },
+		0x81: func() int { gbcpu.ADDrr(&gbcpu.Regs.a, &gbcpu.Regs.c); return 0 },
+		0x82: func() int { gbcpu.ADDrr(&gbcpu.Regs.a, &gbcpu.Regs.d); return 0 },
+		0x83: func() int { gbcpu.ADDrr(&gbcpu.Regs.a, &gbcpu.Regs.e); return 0 },
+		0x84: func() int { gbcpu.ADDrr(&gbcpu.Regs.a, &gbcpu.Regs.h); return 0 },
+		0x85: func() int { gbcpu.ADDrr(&gbcpu.Regs.a, &gbcpu.Regs.l); return 0 },
+		0x86: func() int { gbcpu.ADDAHL(); return 0 },
+		0x87: func() int { gbcpu.ADDrr(&gbcpu.Regs.a, &gbcpu.Regs.a); return 0 },
+		0x88: func() int { gbcpu.ADCrr(&gbcpu.Regs.a, &gbcpu.Regs.b); return 0 },
+		0x89: func() int { gbcpu.ADCrr(&gbcpu.Regs.a, &gbcpu.Regs.c); return 0 },
+		0x8A: func() int { gbcpu.ADCrr(&gbcpu.Regs.a, &gbcpu.Regs.d); return 0 },
+		0x8B: func() int { gbcpu.ADCrr(&gbcpu.Regs.a, &gbcpu.Regs.e); return 0 },
+		0x8C: func() int { gbcpu.ADCrr(&gbcpu.Regs.a, &gbcpu.Regs.h); return 0 },
+		0x8D: func() int { gbcpu.ADCrr(&gbcpu.Regs.a, &gbcpu.Regs.l); return 0 },
+		0x8E: func() int { gbcpu.ADCAHL(); return 0 },
+		0x8F: func() int { gbcpu.ADCrr(&gbcpu.Regs.a, &gbcpu.Regs.a); return 0 },
+		0x90: func() int { gbcpu.SUBr(&gbcpu.Regs.b); return 0 },
+		0x91: func() int { gbcpu.SUBr(&gbcpu.Regs.c); return 0 },
+		0x92: func() int { gbcpu.SUBr(&gbcpu.Regs.d); return 0 },
+		0x93: func() int { gbcpu.SUBr(&gbcpu.Regs.e); return 0 },
+		0x94: func() int { gbcpu.SUBr(&gbcpu.Regs.h); return 0 },
+		0x95: func() int { gbcpu.SUBr(&gbcpu.Regs.l); return 0 },
+		0x96: func() int { gbcpu.SUBHL(); return 0 },
+		0x97: func() int { gbcpu.SUBr(&gbcpu.Regs.a); return 0 },
+		0x98: func() int { gbcpu.SBCrr(&gbcpu.Regs.a, &gbcpu.Regs.b); return 0 },
+		0x99: func() int { gbcpu.SBCrr(&gbcpu.Regs.a, &gbcpu.Regs.c); return 0 },
+		0x9A: func() int { gbcpu.SBCrr(&gbcpu.Regs.a, &gbcpu.Regs.d); return 0 },
+		0x9B: func() int { gbcpu.SBCrr(&gbcpu.Regs.a, &gbcpu.Regs.e); return 0 },
+		0x9C: func() int { gbcpu.SBCrr(&gbcpu.Regs.a, &gbcpu.Regs.h); return 0 },
+		0x9D: func() int { gbcpu.SBCrr(&gbcpu.Regs.a, &gbcpu.Regs.l); return 0 },
+		0x9E: func() int { gbcpu.SBCAHL(); return 0 },
+		0x9F: func() int { gbcpu.SBCrr(&gbcpu.Regs.a, &gbcpu.Regs.a); return 0 },
+		0xA0: func() int { gbcpu.ANDr(&gbcpu.Regs.b); return 0 },
+		0xA1: func() int { gbcpu.ANDr(&gbcpu.Regs.c); return 0 },
+		0xA2: func() int { gbcpu.ANDr(&gbcpu.Regs.d); return 0 },
+		0xA3: func() int { gbcpu.ANDr(&gbcpu.Regs.e); return 0 },
+		0xA4: func() int { gbcpu.ANDr(&gbcpu.Regs.h); return 0 },
+		0xA5: func() int { gbcpu.ANDr(&gbcpu.Regs.l); return 0 },
+		0xA6: func() int { gbcpu.ANDHL(); return 0 },
+		0xA7: func() int { gbcpu.ANDr(&gbcpu.Regs.a); return 0 },
+		0xA8: func() int { gbcpu.XORr(&gbcpu.Regs.b); return 0 },
+		0xA9: func() int { gbcpu.XORr(&gbcpu.Regs.c); return 0 },
+		0xAA: func() int { gbcpu.XORr(&gbcpu.Regs.d); return 0 },
+		0xAB: func() int { gbcpu.XORr(&gbcpu.Regs.e); return 0 },
+		0xAC: func() int { gbcpu.XORr(&gbcpu.Regs.h); return 0 },
+		0xAD: func() int { gbcpu.XORr(&gbcpu.Regs.l); return 0 },
+		0xAE: func() int { gbcpu.XORaa(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0xAF: func() int { gbcpu.XORr(&gbcpu.Regs.a); return 0 },
+		0xB0: func() int { gbcpu.ORr(&gbcpu.Regs.b); return 0 },
+		0xB1: func() int { gbcpu.ORr(&gbcpu.Regs.c); return 0 },
+		0xB2: func() int { gbcpu.ORr(&gbcpu.Regs.d); return 0 },
+		0xB3: func() int { gbcpu.ORr(&gbcpu.Regs.e); return 0 },
+		0xB4: func() int { gbcpu.ORr(&gbcpu.Regs.h); return 0 },
+		0xB5: func() int { gbcpu.ORr(&gbcpu.Regs.l); return 0 },
+		0xB6: func() int { gbcpu.ORHL(); return 0 },
+		0xB7: func() int { gbcpu.ORr(&gbcpu.Regs.a); return 0 },
+		0xB8: func() int { gbcpu.CPr(&gbcpu.Regs.b); return 0 },
+		0xB9: func() int { gbcpu.CPr(&gbcpu.Regs.c); return 0 },
+		0xBA: func() int { gbcpu.CPr(&gbcpu.Regs.d); return 0 },
+		0xBB: func() int { gbcpu.CPr(&gbcpu.Regs.e); return 0 },
+		0xBC: func() int { gbcpu.CPr(&gbcpu.Regs.h); return 0 },
+		0xBD: func() int { gbcpu.CPr(&gbcpu.Regs.l); return 0 },
+		0xBE: func() int { gbcpu.CPaa(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0xBF: func() int { gbcpu.CPr(&gbcpu.Regs.a); return 0 },
+		0xC0: func() int { return gbcpu.RETNZ() },
+		0xC1: func() int { gbcpu.POPrr(&gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0xC2: func() int { return gbcpu.JPNZaa() },
+		0xC3: func() int { gbcpu.JPaa(); return 0 },
+		0xC4: func() int { return gbcpu.CALLNZaa() },
+		0xC5: func() int { gbcpu.PUSHrr(&gbcpu.Regs.b, &gbcpu.Regs.c); return 0 },
+		0xC6: func() int { gbcpu.ADDAn(); return 0 },
+		0xC7: func() int { gbcpu.RST(0x00); return 0 },
+		0xC8: func() int { return gbcpu.RETZ() },
+		0xC9: func() int { gbcpu.RET(); return 0 },
+		0xCA: func() int { return gbcpu.JPZaa() },
+		0xCB: func() int { return gbcpu.CB() },
+		0xCC: func() int { return gbcpu.CALLZaa() },
+		0xCD: func() int { gbcpu.CALLaa(); return 0 },
+		0xCE: func() int { gbcpu.ADCAn(); return 0 },
+		0xCF: func() int { gbcpu.RST(0x08); return 0 },
+		0xD0: func() int { return gbcpu.RETNC() },
+		0xD1: func() int { gbcpu.POPrr(&gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0xD2: func() int { return gbcpu.JPNCaa() },
+		0xD4: func() int { return gbcpu.CALLNCaa() },
+		0xD5: func() int { gbcpu.PUSHrr(&gbcpu.Regs.d, &gbcpu.Regs.e); return 0 },
+		0xD6: func() int { gbcpu.SUBn(); return 0 },
+		0xD7: func() int { gbcpu.RST(0x10); return 0 },
+		0xD8: func() int { return gbcpu.RETC() },
+		0xD9: func() int { gbcpu.RETI(); return 0 },
+		0xDA: func() int { return gbcpu.JPCaa() },
+		0xDC: func() int { return gbcpu.CALLCaa() },
+		0xDE: func() int { gbcpu.SBCAn(); return 0 },
+		0xDF: func() int { gbcpu.RST(0x18); return 0 },
+		0xE0: func() int { gbcpu.LDffnA(); return 0 },
+		0xE1: func() int { gbcpu.POPrr(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0xE2: func() int { gbcpu.LDffCA(); return 0 },
+		0xE5: func() int { gbcpu.PUSHrr(&gbcpu.Regs.h, &gbcpu.Regs.l); return 0 },
+		0xE6: func() int { gbcpu.ANDn(); return 0 },
+		0xE7: func() int { gbcpu.RST(0x20); return 0 },
+		0xE8: func() int { gbcpu.ADDSPs(); return 0 },
+		0xE9: func() int { gbcpu.JPHL(); return 0 },
+		0xEA: func() int { gbcpu.LDaaA(&gbcpu.Regs.a); return 0 },
+		0xEE: func() int { gbcpu.XORn(); return 0 },
+		0xEF: func() int { gbcpu.RST(0x28); return 0 },
+		0xF0: func() int { gbcpu.LDAffn(); return 0 },
+		0xF1: func() int { gbcpu.POPrr(&gbcpu.Regs.a, &gbcpu.Regs.f); return 0 },
+		0xF2: func() int { gbcpu.LDAffC(); return 0 },
+		0xF3: func() int { gbcpu.DI(); return 0 },
+		0xF5: func() int { gbcpu.PUSHrr(&gbcpu.Regs.a, &gbcpu.Regs.f); return 0 },
+		0xF6: func() int { gbcpu.ORn(); return 0 },
+		0xF7: func() int { gbcpu.RST(0x30); return 0 },
+		0xF8: func() int { gbcpu.LDHLSPs(); return 0 },
+		0xF9: func() int { gbcpu.LDSPHL(); return 0 },
+		0xFA: func() int { gbcpu.LDAaa(&gbcpu.Regs.a); return 0 },
+		0xFB: func() int { gbcpu.EI(); return 0 },
+		0xFE: func() int { gbcpu.CPn(); return 0 },
+		0xFF: func() int { gbcpu.RST(0x38); return 0 },
+	}
+
+	gbcpu.executorsCB = [256]func(){
+		0x00: func() { gbcpu.RLCr(&gbcpu.Regs.b) },
+		0x01: func() { gbcpu.RLCr(&gbcpu.Regs.c) },
+		0x02: func() { gbcpu.RLCr(&gbcpu.Regs.d) },
+		0x03: func() { gbcpu.RLCr(&gbcpu.Regs.e) },
+		0x04: func() { gbcpu.RLCr(&gbcpu.Regs.h) },
+		0x05: func() { gbcpu.RLCr(&gbcpu.Regs.l) },
+		0x06: func() { gbcpu.RLCHL() },
+		0x07: func() { gbcpu.RLCr(&gbcpu.Regs.a) },
+		0x08: func() { gbcpu.RRCr(&gbcpu.Regs.b) },
+		0x09: func() { gbcpu.RRCr(&gbcpu.Regs.c) },
+		0x0A: func() { gbcpu.RRCr(&gbcpu.Regs.d) },
+		0x0B: func() { gbcpu.RRCr(&gbcpu.Regs.e) },
+		0x0C: func() { gbcpu.RRCr(&gbcpu.Regs.h) },
+		0x0D: func() { gbcpu.RRCr(&gbcpu.Regs.l) },
+		0x0E: func() { gbcpu.RRCHL() },
+		0x0F: func() { gbcpu.RRCr(&gbcpu.Regs.a) },
+		0x10: func() { gbcpu.RLr(&gbcpu.Regs.b) },
+		0x11: func() { gbcpu.RLr(&gbcpu.Regs.c) },
+		0x12: func() { gbcpu.RLr(&gbcpu.Regs.d) },
+		0x13: func() { gbcpu.RLr(&gbcpu.Regs.e) },
+		0x14: func() { gbcpu.RLr(&gbcpu.Regs.h) },
+		0x15: func() { gbcpu.RLr(&gbcpu.Regs.l) },
+		0x16: func() { gbcpu.RLHL() },
+		0x17: func() { gbcpu.RLr(&gbcpu.Regs.a) },
+		0x18: func() { gbcpu.RRr(&gbcpu.Regs.b) },
+		0x19: func() { gbcpu.RRr(&gbcpu.Regs.c) },
+		0x1A: func() { gbcpu.RRr(&gbcpu.Regs.d) },
+		0x1B: func() { gbcpu.RRr(&gbcpu.Regs.e) },
+		0x1C: func() { gbcpu.RRr(&gbcpu.Regs.h) },
+		0x1D: func() { gbcpu.RRr(&gbcpu.Regs.l) },
+		0x1E: func() { gbcpu.RRHL() },
+		0x1F: func() { gbcpu.RRr(&gbcpu.Regs.a) },
+		0x20: func() { gbcpu.SLAr(&gbcpu.Regs.b) },
+		0x21: func() { gbcpu.SLAr(&gbcpu.Regs.c) },
+		0x22: func() { gbcpu.SLAr(&gbcpu.Regs.d) },
+		0x23: func() { gbcpu.SLAr(&gbcpu.Regs.e) },
+		0x24: func() { gbcpu.SLAr(&gbcpu.Regs.h) },
+		0x25: func() { gbcpu.SLAr(&gbcpu.Regs.l) },
+		0x26: func() { gbcpu.SLAHL() },
+		0x27: func() { gbcpu.SLAr(&gbcpu.Regs.a) },
+		0x28: func() { gbcpu.SRAr(&gbcpu.Regs.b) },
+		0x29: func() { gbcpu.SRAr(&gbcpu.Regs.c) },
+		0x2A: func() { gbcpu.SRAr(&gbcpu.Regs.d) },
+		0x2B: func() { gbcpu.SRAr(&gbcpu.Regs.e) },
+		0x2C: func() { gbcpu.SRAr(&gbcpu.Regs.h) },
+		0x2D: func() { gbcpu.SRAr(&gbcpu.Regs.l) },
+		0x2E: func() { gbcpu.SRAHL() },
+		0x2F: func() { gbcpu.SRAr(&gbcpu.Regs.a) },
+		0x30: func() { gbcpu.SWAPr(&gbcpu.Regs.b) },
+		0x31: func() { gbcpu.SWAPr(&gbcpu.Regs.c) },
+		0x32: func() { gbcpu.SWAPr(&gbcpu.Regs.d) },
+		0x33: func() { gbcpu.SWAPr(&gbcpu.Regs.e) },
+		0x34: func() { gbcpu.SWAPr(&gbcpu.Regs.h) },
+		0x35: func() { gbcpu.SWAPr(&gbcpu.Regs.l) },
+		0x36: func() { gbcpu.SWAPHL() },
+		0x37: func() { gbcpu.SWAPr(&gbcpu.Regs.a) },
+		0x38: func() { gbcpu.SRLr(&gbcpu.Regs.b) },
+		0x39: func() { gbcpu.SRLr(&gbcpu.Regs.c) },
+		0x3A: func() { gbcpu.SRLr(&gbcpu.Regs.d) },
+		0x3B: func() { gbcpu.SRLr(&gbcpu.Regs.e) },
+		0x3C: func() { gbcpu.SRLr(&gbcpu.Regs.h) },
+		0x3D: func() { gbcpu.SRLr(&gbcpu.Regs.l) },
+		0x3E: func() { gbcpu.SRLHL() },
+		0x3F: func() { gbcpu.SRLr(&gbcpu.Regs.a) },
+		0x40: func() { gbcpu.BITnr(0, &gbcpu.Regs.b) },
+		0x41: func() { gbcpu.BITnr(0, &gbcpu.Regs.c) },
+		0x42: func() { gbcpu.BITnr(0, &gbcpu.Regs.d) },
+		0x43: func() { gbcpu.BITnr(0, &gbcpu.Regs.e) },
+		0x44: func() { gbcpu.BITnr(0, &gbcpu.Regs.h) },
+		0x45: func() { gbcpu.BITnr(0, &gbcpu.Regs.l) },
+		0x46: func() { gbcpu.BITHL(0) },
+		0x47: func() { gbcpu.BITnr(0, &gbcpu.Regs.a) },
+		0x48: func() { gbcpu.BITnr(1, &gbcpu.Regs.b) },
+		0x49: func() { gbcpu.BITnr(1, &gbcpu.Regs.c) },
+		0x4A: func() { gbcpu.BITnr(1, &gbcpu.Regs.d) },
+		0x4B: func() { gbcpu.BITnr(1, &gbcpu.Regs.e) },
+		0x4C: func() { gbcpu.BITnr(1, &gbcpu.Regs.h) },
+		0x4D: func() { gbcpu.BITnr(1, &gbcpu.Regs.l) },
+		0x4E: func() { gbcpu.BITHL(1) },
+		0x4F: func() { gbcpu.BITnr(1, &gbcpu.Regs.a) },
+		0x50: func() { gbcpu.BITnr(2, &gbcpu.Regs.b) },
+		0x51: func() { gbcpu.BITnr(2, &gbcpu.Regs.c) },
+		0x52: func() { gbcpu.BITnr(2, &gbcpu.Regs.d) },
+		0x53: func() { gbcpu.BITnr(2, &gbcpu.Regs.e) },
+		0x54: func() { gbcpu.BITnr(2, &gbcpu.Regs.h) },
+		0x55: func() { gbcpu.BITnr(2, &gbcpu.Regs.l) },
+		0x56: func() { gbcpu.BITHL(2) },
+		0x57: func() { gbcpu.BITnr(2, &gbcpu.Regs.a) },
+		0x58: func() { gbcpu.BITnr(3, &gbcpu.Regs.b) },
+		0x59: func() { gbcpu.BITnr(3, &gbcpu.Regs.c) },
+		0x5A: func() { gbcpu.BITnr(3, &gbcpu.Regs.d) },
+		0x5B: func() { gbcpu.BITnr(3, &gbcpu.Regs.e) },
+		0x5C: func() { gbcpu.BITnr(3, &gbcpu.Regs.h) },
+		0x5D: func() { gbcpu.BITnr(3, &gbcpu.Regs.l) },
+		0x5E: func() { gbcpu.BITHL(3) },
+		0x5F: func() { gbcpu.BITnr(3, &gbcpu.Regs.a) },
+		0x60: func() { gbcpu.BITnr(4, &gbcpu.Regs.b) },
+		0x61: func() { gbcpu.BITnr(4, &gbcpu.Regs.c) },
+		0x62: func() { gbcpu.BITnr(4, &gbcpu.Regs.d) },
+		0x63: func() { gbcpu.BITnr(4, &gbcpu.Regs.e) },
+		0x64: func() { gbcpu.BITnr(4, &gbcpu.Regs.h) },
+		0x65: func() { gbcpu.BITnr(4, &gbcpu.Regs.l) },
+		0x66: func() { gbcpu.BITHL(4) },
+		0x67: func() { gbcpu.BITnr(4, &gbcpu.Regs.a) },
+		0x68: func() { gbcpu.BITnr(5, &gbcpu.Regs.b) },
+		0x69: func() { gbcpu.BITnr(5, &gbcpu.Regs.c) },
+		0x6A: func() { gbcpu.BITnr(5, &gbcpu.Regs.d) },
+		0x6B: func() { gbcpu.BITnr(5, &gbcpu.Regs.e) },
+		0x6C: func() { gbcpu.BITnr(5, &gbcpu.Regs.h) },
+		0x6D: func() { gbcpu.BITnr(5, &gbcpu.Regs.l) },
+		0x6E: func() { gbcpu.BITHL(5) },
+		0x6F: func() { gbcpu.BITnr(5, &gbcpu.Regs.a) },
+		0x70: func() { gbcpu.BITnr(6, &gbcpu.Regs.b) },
+		0x71: func() { gbcpu.BITnr(6, &gbcpu.Regs.c) },
+		0x72: func() { gbcpu.BITnr(6, &gbcpu.Regs.d) },
+		0x73: func() { gbcpu.BITnr(6, &gbcpu.Regs.e) },
+		0x74: func() { gbcpu.BITnr(6, &gbcpu.Regs.h) },
+		0x75: func() { gbcpu.BITnr(6, &gbcpu.Regs.l) },
+		0x76: func() { gbcpu.BITHL(6) },
+		0x77: func() { gbcpu.BITnr(6, &gbcpu.Regs.a) },
+		0x78: func() { gbcpu.BITnr(7, &gbcpu.Regs.b) },
+		0x79: func() { gbcpu.BITnr(7, &gbcpu.Regs.c) },
+		0x7A: func() { gbcpu.BITnr(7, &gbcpu.Regs.d) },
+		0x7B: func() { gbcpu.BITnr(7, &gbcpu.Regs.e) },
+		0x7C: func() { gbcpu.BITnr(7, &gbcpu.Regs.h) },
+		0x7D: func() { gbcpu.BITnr(7, &gbcpu.Regs.l) },
+		0x7E: func() { gbcpu.BITHL(7) },
+		0x7F: func() { gbcpu.BITnr(7, &gbcpu.Regs.a) },
+		0x80: func() { gbcpu.RESnr(0, &gbcpu.Regs.b) },
+		0x81: func() { gbcpu.RESnr(0, &gbcpu.Regs.c) },
+		0x82: func() { gbcpu.RESnr(0, &gbcpu.Regs.d) },
+		0x83: func() { gbcpu.RESnr(0, &gbcpu.Regs.e) },
+		0x84: func() { gbcpu.RESnr(0, &gbcpu.Regs.h) },
+		0x85: func() { gbcpu.RESnr(0, &gbcpu.Regs.l) },
+		0x86: func() { gbcpu.RESHL(0) },
+		0x87: func() { gbcpu.RESnr(0, &gbcpu.Regs.a) },
+		0x88: func() { gbcpu.RESnr(1, &gbcpu.Regs.b) },
+		0x89: func() { gbcpu.RESnr(1, &gbcpu.Regs.c) },
+		0x8A: func() { gbcpu.RESnr(1, &gbcpu.Regs.d) },
+		0x8B: func() { gbcpu.RESnr(1, &gbcpu.Regs.e) },
+		0x8C: func() { gbcpu.RESnr(1, &gbcpu.Regs.h) },
+		0x8D: func() { gbcpu.RESnr(1, &gbcpu.Regs.l) },
+		0x8E: func() { gbcpu.RESHL(1) },
+		0x8F: func() { gbcpu.RESnr(1, &gbcpu.Regs.a) },
+		0x90: func() { gbcpu.RESnr(2, &gbcpu.Regs.b) },
+		0x91: func() { gbcpu.RESnr(2, &gbcpu.Regs.c) },
+		0x92: func() { gbcpu.RESnr(2, &gbcpu.Regs.d) },
+		0x93: func() { gbcpu.RESnr(2, &gbcpu.Regs.e) },
+		0x94: func() { gbcpu.RESnr(2, &gbcpu.Regs.h) },
+		0x95: func() { gbcpu.RESnr(2, &gbcpu.Regs.l) },
+		0x96: func() { gbcpu.RESHL(2) },
+		0x97: func() { gbcpu.RESnr(2, &gbcpu.Regs.a) },
+		0x98: func() { gbcpu.RESnr(3, &gbcpu.Regs.b) },
+		0x99: func() { gbcpu.RESnr(3, &gbcpu.Regs.c) },
+		0x9A: func() { gbcpu.RESnr(3, &gbcpu.Regs.d) },
+		0x9B: func() { gbcpu.RESnr(3, &gbcpu.Regs.e) },
+		0x9C: func() { gbcpu.RESnr(3, &gbcpu.Regs.h) },
+		0x9D: func() { gbcpu.RESnr(3, &gbcpu.Regs.l) },
+		0x9E: func() { gbcpu.RESHL(3) },
+		0x9F: func() { gbcpu.RESnr(3, &gbcpu.Regs.a) },
+		0xA0: func() { gbcpu.RESnr(4, &gbcpu.Regs.b) },
+		0xA1: func() { gbcpu.RESnr(4, &gbcpu.Regs.c) },
+		0xA2: func() { gbcpu.RESnr(4, &gbcpu.Regs.d) },
+		0xA3: func() { gbcpu.RESnr(4, &gbcpu.Regs.e) },
+		0xA4: func() { gbcpu.RESnr(4, &gbcpu.Regs.h) },
+		0xA5: func() { gbcpu.RESnr(4, &gbcpu.Regs.l) },
+		0xA6: func() { gbcpu.RESHL(4) },
+		0xA7: func() { gbcpu.RESnr(4, &gbcpu.Regs.a) },
+		0xA8: func() { gbcpu.RESnr(5, &gbcpu.Regs.b) },
+		0xA9: func() { gbcpu.RESnr(5, &gbcpu.Regs.c) },
+		0xAA: func() { gbcpu.RESnr(5, &gbcpu.Regs.d) },
+		0xAB: func() { gbcpu.RESnr(5, &gbcpu.Regs.e) },
+		0xAC: func() { gbcpu.RESnr(5, &gbcpu.Regs.h) },
+		0xAD: func() { gbcpu.RESnr(5, &gbcpu.Regs.l) },
+		0xAE: func() { gbcpu.RESHL(5) },
+		0xAF: func() { gbcpu.RESnr(5, &gbcpu.Regs.a) },
+		0xB0: func() { gbcpu.RESnr(6, &gbcpu.Regs.b) },
+		0xB1: func() { gbcpu.RESnr(6, &gbcpu.Regs.c) },
+		0xB2: func() { gbcpu.RESnr(6, &gbcpu.Regs.d) },
+		0xB3: func() { gbcpu.RESnr(6, &gbcpu.Regs.e) },
+		0xB4: func() { gbcpu.RESnr(6, &gbcpu.Regs.h) },
+		0xB5: func() { gbcpu.RESnr(6, &gbcpu.Regs.l) },
+		0xB6: func() { gbcpu.RESHL(6) },
+		0xB7: func() { gbcpu.RESnr(6, &gbcpu.Regs.a) },
+		0xB8: func() { gbcpu.RESnr(7, &gbcpu.Regs.b) },
+		0xB9: func() { gbcpu.RESnr(7, &gbcpu.Regs.c) },
+		0xBA: func() { gbcpu.RESnr(7, &gbcpu.Regs.d) },
+		0xBB: func() { gbcpu.RESnr(7, &gbcpu.Regs.e) },
+		0xBC: func() { gbcpu.RESnr(7, &gbcpu.Regs.h) },
+		0xBD: func() { gbcpu.RESnr(7, &gbcpu.Regs.l) },
+		0xBE: func() { gbcpu.RESHL(7) },
+		0xBF: func() { gbcpu.RESnr(7, &gbcpu.Regs.a) },
+		0xC0: func() { gbcpu.SETnr(0, &gbcpu.Regs.b) },
+		0xC1: func() { gbcpu.SETnr(0, &gbcpu.Regs.c) },
+		0xC2: func() { gbcpu.SETnr(0, &gbcpu.Regs.d) },
+		0xC3: func() { gbcpu.SETnr(0, &gbcpu.Regs.e) },
+		0xC4: func() { gbcpu.SETnr(0, &gbcpu.Regs.h) },
+		0xC5: func() { gbcpu.SETnr(0, &gbcpu.Regs.l) },
+		0xC6: func() { gbcpu.SETHL(0) },
+		0xC7: func() { gbcpu.SETnr(0, &gbcpu.Regs.a) },
+		0xC8: func() { gbcpu.SETnr(1, &gbcpu.Regs.b) },
+		0xC9: func() { gbcpu.SETnr(1, &gbcpu.Regs.c) },
+		0xCA: func() { gbcpu.SETnr(1, &gbcpu.Regs.d) },
+		0xCB: func() { gbcpu.SETnr(1, &gbcpu.Regs.e) },
+		0xCC: func() { gbcpu.SETnr(1, &gbcpu.Regs.h) },
+		0xCD: func() { gbcpu.SETnr(1, &gbcpu.Regs.l) },
+		0xCE: func() { gbcpu.SETHL(1) },
+		0xCF: func() { gbcpu.SETnr(1, &gbcpu.Regs.a) },
+		0xD0: func() { gbcpu.SETnr(2, &gbcpu.Regs.b) },
+		0xD1: func() { gbcpu.SETnr(2, &gbcpu.Regs.c) },
+		0xD2: func() { gbcpu.SETnr(2, &gbcpu.Regs.d) },
+		0xD3: func() { gbcpu.SETnr(2, &gbcpu.Regs.e) },
+		0xD4: func() { gbcpu.SETnr(2, &gbcpu.Regs.h) },
+		0xD5: func() { gbcpu.SETnr(2, &gbcpu.Regs.l) },
+		0xD6: func() { gbcpu.SETHL(2) },
+		0xD7: func() { gbcpu.SETnr(2, &gbcpu.Regs.a) },
+		0xD8: func() { gbcpu.SETnr(3, &gbcpu.Regs.b) },
+		0xD9: func() { gbcpu.SETnr(3, &gbcpu.Regs.c) },
+		0xDA: func() { gbcpu.SETnr(3, &gbcpu.Regs.d) },
+		0xDB: func() { gbcpu.SETnr(3, &gbcpu.Regs.e) },
+		0xDC: func() { gbcpu.SETnr(3, &gbcpu.Regs.h) },
+		0xDD: func() { gbcpu.SETnr(3, &gbcpu.Regs.l) },
+		0xDE: func() { gbcpu.SETHL(3) },
+		0xDF: func() { gbcpu.SETnr(3, &gbcpu.Regs.a) },
+		0xE0: func() { gbcpu.SETnr(4, &gbcpu.Regs.b) },
+		0xE1: func() { gbcpu.SETnr(4, &gbcpu.Regs.c) },
+		0xE2: func() { gbcpu.SETnr(4, &gbcpu.Regs.d) },
+		0xE3: func() { gbcpu.SETnr(4, &gbcpu.Regs.e) },
+		0xE4: func() { gbcpu.SETnr(4, &gbcpu.Regs.h) },
+		0xE5: func() { gbcpu.SETnr(4, &gbcpu.Regs.l) },
+		0xE6: func() { gbcpu.SETHL(4) },
+		0xE7: func() { gbcpu.SETnr(4, &gbcpu.Regs.a) },
+		0xE8: func() { gbcpu.SETnr(5, &gbcpu.Regs.b) },
+		0xE9: func() { gbcpu.SETnr(5, &gbcpu.Regs.c) },
+		0xEA: func() { gbcpu.SETnr(5, &gbcpu.Regs.d) },
+		0xEB: func() { gbcpu.SETnr(5, &gbcpu.Regs.e) },
+		0xEC: func() { gbcpu.SETnr(5, &gbcpu.Regs.h) },
+		0xED: func() { gbcpu.SETnr(5, &gbcpu.Regs.l) },
+		0xEE: func() { gbcpu.SETHL(5) },
+		0xEF: func() { gbcpu.SETnr(5, &gbcpu.Regs.a) },
+		0xF0: func() { gbcpu.SETnr(6, &gbcpu.Regs.b) },
+		0xF1: func() { gbcpu.SETnr(6, &gbcpu.Regs.c) },
+		0xF2: func() { gbcpu.SETnr(6, &gbcpu.Regs.d) },
+		0xF3: func() { gbcpu.SETnr(6, &gbcpu.Regs.e) },
+		0xF4: func() { gbcpu.SETnr(6, &gbcpu.Regs.h) },
+		0xF5: func() { gbcpu.SETnr(6, &gbcpu.Regs.l) },
+		0xF6: func() { gbcpu.SETHL(6) },
+		0xF7: func() { gbcpu.SETnr(6, &gbcpu.Regs.a) },
+		0xF8: func() { gbcpu.SETnr(7, &gbcpu.Regs.b) },
+		0xF9: func() { gbcpu.SETnr(7, &gbcpu.Regs.c) },
+		0xFA: func() { gbcpu.SETnr(7, &gbcpu.Regs.d) },
+		0xFB: func() { gbcpu.SETnr(7, &gbcpu.Regs.e) },
+		0xFC: func() { gbcpu.SETnr(7, &gbcpu.Regs.h) },
+		0xFD: func() { gbcpu.SETnr(7, &gbcpu.Regs.l) },
+		0xFE: func() { gbcpu.SETHL(7) },
+		0xFF: func() { gbcpu.SETnr(7, &gbcpu.Regs.a) },
+	}
+}
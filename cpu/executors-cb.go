@@ -32,7 +32,7 @@ func (gbcpu *GBCPU) RLCr(reg *byte) {
 // Rotated bit is copied to carry
 // Flags: Z00C
 func (gbcpu *GBCPU) RLCHL() {
-	gbcpu.RLCr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.RLCr)
 }
 
 // RRCr -> e.g. RRC B
@@ -64,7 +64,7 @@ func (gbcpu *GBCPU) RRCr(reg *byte) {
 // Rotated bit is copied to carry
 // Flags: Z00C
 func (gbcpu *GBCPU) RRCHL() {
-	gbcpu.RRCr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.RRCr)
 }
 
 // RLr -> e.g. RL B
@@ -99,7 +99,7 @@ func (gbcpu *GBCPU) RLr(reg *byte) {
 // Old carry becomes new 7th bit
 // Flags: Z00C
 func (gbcpu *GBCPU) RLHL() {
-	gbcpu.RLr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.RLr)
 }
 
 // RRr -> e.g. RR B
@@ -132,7 +132,7 @@ func (gbcpu *GBCPU) RRr(reg *byte) {
 // Old carry becomes new 7th bit
 // Flags: Z00C
 func (gbcpu *GBCPU) RRHL() {
-	gbcpu.RRr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.RRr)
 }
 
 // SLAr -> e.g. SLA B
@@ -163,7 +163,7 @@ func (gbcpu *GBCPU) SLAr(reg *byte) {
 // Least significant bit of reg set to 0
 // Flags: Z00C
 func (gbcpu *GBCPU) SLAHL() {
-	gbcpu.SLAr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.SLAr)
 }
 
 // SRAr -> e.g. SRA B
@@ -194,7 +194,7 @@ func (gbcpu *GBCPU) SRAr(reg *byte) {
 // Most significant bit of reg is unaffected
 // Flags: Z000
 func (gbcpu *GBCPU) SRAHL() {
-	gbcpu.SRAr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.SRAr)
 }
 
 // SWAPr -> e.g. SWAP B
@@ -218,7 +218,7 @@ func (gbcpu *GBCPU) SWAPr(reg *byte) {
 // Swap nibbles of value at addr (HL)
 // Flags: Z000
 func (gbcpu *GBCPU) SWAPHL() {
-	gbcpu.SWAPr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.SWAPr)
 }
 
 // SRLr -> e.g. SRL B
@@ -249,7 +249,7 @@ func (gbcpu *GBCPU) SRLr(reg *byte) {
 // Most significant bit of reg is set to 0
 // Flags: Z00C
 func (gbcpu *GBCPU) SRLHL() {
-	gbcpu.SRLr(&GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(gbcpu.SRLr)
 }
 
 // BITnr -> e.g. BIT 0,B
@@ -272,7 +272,8 @@ func (gbcpu *GBCPU) BITnr(pos uint8, reg *byte) {
 // Test bit at position in value at addr (HL)
 // Flags: Z01-
 func (gbcpu *GBCPU) BITHL(pos uint8) {
-	gbcpu.BITnr(pos, &GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	val := gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
+	gbcpu.BITnr(pos, &val)
 }
 
 // RESnr -> e.g. RES 0,B
@@ -286,7 +287,7 @@ func (gbcpu *GBCPU) RESnr(pos uint8, reg *byte) {
 // Reset bit in value at addr (HL)
 // Flags: ----
 func (gbcpu *GBCPU) RESHL(pos uint8) {
-	gbcpu.RESnr(pos, &GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(func(val *byte) { gbcpu.RESnr(pos, val) })
 }
 
 // SETnr -> e.g. SET 0,B
@@ -300,5 +301,5 @@ func (gbcpu *GBCPU) SETnr(pos uint8, reg *byte) {
 // Set bit in value at addr (HL)
 // Flags: ----
 func (gbcpu *GBCPU) SETHL(pos uint8) {
-	gbcpu.SETnr(pos, &GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)])
+	gbcpu.modifyHL(func(val *byte) { gbcpu.SETnr(pos, val) })
 }
@@ -2,12 +2,11 @@
 // Sharp LR35902 instructions
 // Non-CB prefixed means these are only run if the instruction we received
 // is not `CB .. ..`
-// Executor function field on the Instruction struct executes these
+// The executor tables in dispatch.go run these
 package cpu
 
 import (
-	"encoding/binary"
-
+	"../interrupts"
 	"../mmu"
 )
 
@@ -15,6 +14,15 @@ import (
 // Prevents having to set MMU pointer as a field on the CPU struct
 var GbMMU *mmu.GBMMU
 
+// GbBus variable injection from main.go
+// All memory accesses made by instructions go through this, which is GbMMU
+// unless the CPU is being tested on its own, see the cputest package
+var GbBus Bus
+
+// GbInterrupts variable injection from main.go
+// The CPU checks for pending interrupts and acknowledges them when serviced
+var GbInterrupts *interrupts.GBInterrupts
+
 // LDrr -> e.g. LD A,B
 // Loads value in one register to another
 func (gbcpu *GBCPU) LDrr(to, from *byte) {
@@ -24,15 +32,13 @@ func (gbcpu *GBCPU) LDrr(to, from *byte) {
 // LDrrnn -> e.g. LD BC,i16
 // Loads 2 8-bit immediate operands into register pair
 func (gbcpu *GBCPU) LDrrnn(reg1, reg2 *byte) {
-	operands := gbcpu.getOperands(2)
-	*reg1 = operands[1]
-	*reg2 = operands[0]
+	*reg1, *reg2 = gbcpu.Regs.SplitWord(gbcpu.getOperandWord())
 }
 
 // LDSPHL -> e.g. LD SP,HL
 // Loads bytes from register pair HL into SP
 func (gbcpu *GBCPU) LDSPHL() {
-	gbcpu.Regs.sp = []byte{gbcpu.Regs.l, gbcpu.Regs.h}
+	gbcpu.Regs.sp = gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
 }
 
 // LDHLSPs -> e.g. LD BC,SP+s8
@@ -40,8 +46,8 @@ func (gbcpu *GBCPU) LDSPHL() {
 // HC and C are a little weird for this instruction
 // https://stackoverflow.com/questions/5159603/gbz80-how-does-ld-hl-spe-affect-h-and-c-flags
 func (gbcpu *GBCPU) LDHLSPs() {
-	operand := gbcpu.getOperands(1)[0]
-	sp := int(binary.LittleEndian.Uint16(gbcpu.Regs.sp))
+	operand := gbcpu.getOperand()
+	sp := int(gbcpu.Regs.sp)
 	result := 0
 
 	if operand > 127 {
@@ -73,9 +79,7 @@ func (gbcpu *GBCPU) LDHLSPs() {
 // LDrn -> e.g. LD B,i8
 // Loads 1 8-bit immediate operand into a register
 func (gbcpu *GBCPU) LDrn(reg *byte) {
-	operand := gbcpu.getOperands(1)
-	*reg = operand[0]
-
+	*reg = gbcpu.getOperand()
 }
 
 // INCrr -> e.g. INC BC
@@ -129,17 +133,18 @@ func (gbcpu *GBCPU) INCr(reg *byte) {
 // Flags: Z0H-
 func (gbcpu *GBCPU) INCHL() {
 	addr := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
-	result := GbMMU.Memory[addr] + 1
+	val := gbcpu.read(addr)
+	result := val + 1
 
-	if (result^0x01^GbMMU.Memory[addr])&0x10 == 0x10 {
+	if (result^0x01^val)&0x10 == 0x10 {
 		gbcpu.Regs.setHalfCarry()
 	} else {
 		gbcpu.Regs.clearHalfCarry()
 	}
 
-	GbMMU.WriteData(addr, result)
+	gbcpu.write(addr, result)
 
-	if GbMMU.Memory[addr] == 0 {
+	if result == 0 {
 		gbcpu.Regs.setZero()
 	} else {
 		gbcpu.Regs.clearZero()
@@ -153,17 +158,18 @@ func (gbcpu *GBCPU) INCHL() {
 // Flags: Z1H-
 func (gbcpu *GBCPU) DECHL() {
 	addr := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
-	result := GbMMU.Memory[addr] - 1
+	val := gbcpu.read(addr)
+	result := val - 1
 
-	if (result^0x01^GbMMU.Memory[addr])&0x10 == 0x10 {
+	if (result^0x01^val)&0x10 == 0x10 {
 		gbcpu.Regs.setHalfCarry()
 	} else {
 		gbcpu.Regs.clearHalfCarry()
 	}
 
-	GbMMU.WriteData(addr, result)
+	gbcpu.write(addr, result)
 
-	if GbMMU.Memory[addr] == 0 {
+	if result == 0 {
 		gbcpu.Regs.setZero()
 	} else {
 		gbcpu.Regs.clearZero()
@@ -176,18 +182,14 @@ func (gbcpu *GBCPU) DECHL() {
 // Increment value of stack pointer by 1
 // Flags: none
 func (gbcpu *GBCPU) INCSP() {
-	newSP := gbcpu.sliceToInt(gbcpu.Regs.sp)
-	newSP++
-	binary.LittleEndian.PutUint16(gbcpu.Regs.sp, newSP)
+	gbcpu.Regs.sp++
 }
 
 // DECSP -> e.g. DEC SP
 // Decrement value of stack pointer by 1
 // Flags: none
 func (gbcpu *GBCPU) DECSP() {
-	newSP := gbcpu.sliceToInt(gbcpu.Regs.sp)
-	newSP--
-	binary.LittleEndian.PutUint16(gbcpu.Regs.sp, newSP)
+	gbcpu.Regs.sp--
 }
 
 // DECr -> e.g. DEC B
@@ -322,30 +324,11 @@ func (gbcpu *GBCPU) RRA() {
 // Handles 0000,0008,0010,0018,0020,0028,0030,0038 which come from ROM
 // Flags: none
 func (gbcpu *GBCPU) RST(imm byte) {
-	nextInstr := gbcpu.sliceToInt(gbcpu.Regs.PC) + 1
-	nextInstrBytes := make([]byte, 2)
-	binary.LittleEndian.PutUint16(nextInstrBytes, nextInstr)
-	gbcpu.pushByteToStack(nextInstrBytes[1])
-	gbcpu.pushByteToStack(nextInstrBytes[0])
-
-	gbcpu.Regs.PC = []byte{imm, 0x00}
-	gbcpu.Jumped = true
-}
-
-// RSTI runs interrupt handlers
-// Does not have a corresponding opcode, but is slightly different than RST
-// Flags: none
-func (gbcpu *GBCPU) RSTI(addr byte) {
-	// Disable further interrupts while vblank is executing
-	gbcpu.IME = 0
-	gbcpu.EIReceived = false
+	nextInstr := gbcpu.Regs.pc + 1
+	gbcpu.tick(4)
+	gbcpu.pushWordToStack(nextInstr)
 
-	// Push current PC to stack
-	gbcpu.pushByteToStack(gbcpu.Regs.PC[1])
-	gbcpu.pushByteToStack(gbcpu.Regs.PC[0])
-
-	// Jump to vblank handler address
-	gbcpu.Regs.PC = []byte{addr, 0x00}
+	gbcpu.Regs.pc = uint16(imm)
 	gbcpu.Jumped = true
 }
 
@@ -354,18 +337,16 @@ func (gbcpu *GBCPU) RSTI(addr byte) {
 // Since SP is 2 bytes, we write to (a16) and (a16 + 1)
 // Flags: none
 func (gbcpu *GBCPU) LDaaSP() {
-	operands := gbcpu.getOperands(2)
-	addrInc := binary.LittleEndian.Uint16(operands) + 1
-	GbMMU.WriteData(gbcpu.sliceToInt(operands), gbcpu.Regs.sp[0])
-	GbMMU.WriteData(addrInc, gbcpu.Regs.sp[1])
+	addr := gbcpu.getOperandWord()
+	gbcpu.write(addr, byte(gbcpu.Regs.sp))
+	gbcpu.write(addr+1, byte(gbcpu.Regs.sp>>8))
 }
 
 // LDSPnn -> e.g. LD SP,i16
 // Loads 16 bit value from next 2 bytes into SP
 // Flags: none
 func (gbcpu *GBCPU) LDSPnn() {
-	operands := gbcpu.getOperands(2)
-	gbcpu.Regs.sp = operands
+	gbcpu.Regs.sp = gbcpu.getOperandWord()
 }
 
 // ADDrr -> e.g. ADD A,B
@@ -412,7 +393,7 @@ func (gbcpu *GBCPU) ADDrr(reg1, reg2 *byte) {
 // Flags: Z0HC
 func (gbcpu *GBCPU) ADDAn() {
 	oldVal := gbcpu.Regs.a
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 	hc := (((gbcpu.Regs.a & 0xf) + (operand & 0xf)) & 0x10) == 0x10
 	gbcpu.Regs.a = gbcpu.Regs.a + operand
 
@@ -449,7 +430,7 @@ func (gbcpu *GBCPU) ADDAn() {
 // Flags: Z0HC
 func (gbcpu *GBCPU) ADCAn() {
 	carry := int(gbcpu.Regs.getCarry())
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 
 	// Check for carry
 	if ((int(gbcpu.Regs.a) & 0xFF) + (int(operand) & 0xFF) + carry) > 0xFF {
@@ -515,7 +496,7 @@ func (gbcpu *GBCPU) ADCrr(reg1, reg2 *byte) {
 // Flags: Z0HC
 func (gbcpu *GBCPU) ADCAHL() {
 	carry := int(gbcpu.Regs.getCarry())
-	operand := GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)]
+	operand := gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
 
 	// Check for carry
 	if ((int(gbcpu.Regs.a) & 0xFF) + (int(operand) & 0xFF) + carry) > 0xFF {
@@ -550,7 +531,7 @@ func (gbcpu *GBCPU) ADCAHL() {
 // Adds value at addr (HL) to reg A
 // Flags: Z0HC
 func (gbcpu *GBCPU) ADDAHL() {
-	operand := GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)]
+	operand := gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
 	oldVal := gbcpu.Regs.a
 	result := gbcpu.Regs.a + operand
 	hc := (((gbcpu.Regs.a & 0xf) + (operand & 0xf)) & 0x10) == 0x10
@@ -619,8 +600,8 @@ func (gbcpu *GBCPU) ADDHLrr(reg1, reg2 *byte) {
 // Sets SP to new value
 // Flags: 00HC
 func (gbcpu *GBCPU) ADDSPs() {
-	operand := gbcpu.getOperands(1)[0]
-	sp := int(binary.LittleEndian.Uint16(gbcpu.Regs.sp))
+	operand := gbcpu.getOperand()
+	sp := int(gbcpu.Regs.sp)
 	result := 0
 
 	if operand > 127 {
@@ -643,7 +624,7 @@ func (gbcpu *GBCPU) ADDSPs() {
 		gbcpu.Regs.clearHalfCarry()
 	}
 
-	binary.LittleEndian.PutUint16(gbcpu.Regs.sp, uint16(result))
+	gbcpu.Regs.sp = uint16(result)
 
 	gbcpu.Regs.clearZero()
 	gbcpu.Regs.clearSubtract()
@@ -654,7 +635,7 @@ func (gbcpu *GBCPU) ADDSPs() {
 // Flags: -0HC
 func (gbcpu *GBCPU) ADDHLSP() {
 	hl := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
-	sp := binary.LittleEndian.Uint16(gbcpu.Regs.sp)
+	sp := gbcpu.Regs.sp
 	result := hl + sp
 
 	if result < hl {
@@ -697,8 +678,7 @@ func (gbcpu *GBCPU) ANDr(reg *byte) {
 // Bitwise AND of i8 into A
 // Flags: Z010
 func (gbcpu *GBCPU) ANDn() {
-	operands := gbcpu.getOperands(1)
-	gbcpu.Regs.a &= operands[0]
+	gbcpu.Regs.a &= gbcpu.getOperand()
 
 	// Check for zero
 	if gbcpu.Regs.a == 0x00 {
@@ -717,7 +697,7 @@ func (gbcpu *GBCPU) ANDn() {
 // Bitwise AND of value at addr (HL) into A
 // Flags: Z010
 func (gbcpu *GBCPU) ANDHL() {
-	val := GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)]
+	val := gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
 	gbcpu.Regs.a &= val
 
 	if gbcpu.Regs.a == 0 {
@@ -754,8 +734,7 @@ func (gbcpu *GBCPU) ORr(reg *byte) {
 // Bitwise OR of i8 into A
 // Flags: Z000
 func (gbcpu *GBCPU) ORn() {
-	operand := gbcpu.getOperands(1)
-	gbcpu.Regs.a |= operand[0]
+	gbcpu.Regs.a |= gbcpu.getOperand()
 
 	// Check for zero
 	if gbcpu.Regs.a == 0x00 {
@@ -774,7 +753,7 @@ func (gbcpu *GBCPU) ORn() {
 // Bitwise OR of byte at addr
 // Flags: Z000
 func (gbcpu *GBCPU) ORHL() {
-	val := GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)]
+	val := gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
 	gbcpu.Regs.a |= val
 
 	if gbcpu.Regs.a == 0 {
@@ -811,8 +790,7 @@ func (gbcpu *GBCPU) XORr(reg *byte) {
 // Bitwise XOR of i8 into A
 // Flags: Z000
 func (gbcpu *GBCPU) XORn() {
-	operand := gbcpu.getOperands(1)
-	gbcpu.Regs.a ^= operand[0]
+	gbcpu.Regs.a ^= gbcpu.getOperand()
 
 	// Check for zero
 	if gbcpu.Regs.a == 0x00 {
@@ -831,7 +809,7 @@ func (gbcpu *GBCPU) XORn() {
 // Bitwise XOR of value at addr a1a2 into A
 // Flags: Z000
 func (gbcpu *GBCPU) XORaa(a1, a2 *byte) {
-	val := GbMMU.Memory[binary.LittleEndian.Uint16([]byte{*a2, *a1})]
+	val := gbcpu.read(gbcpu.Regs.JoinRegs(a1, a2))
 	gbcpu.Regs.a ^= val
 
 	// Check for zero
@@ -852,7 +830,7 @@ func (gbcpu *GBCPU) XORaa(a1, a2 *byte) {
 // Result is written into reg
 // Flags: Z1HC
 func (gbcpu *GBCPU) SUBn() {
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 
 	if (int(gbcpu.Regs.a) & 0xFF) < (int(operand) & 0xFF) {
 		gbcpu.Regs.setCarry()
@@ -912,7 +890,7 @@ func (gbcpu *GBCPU) SUBr(reg *byte) {
 // Write result to A
 // Flags: Z1HC
 func (gbcpu *GBCPU) SUBHL() {
-	operand := GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)]
+	operand := gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
 	oldVal := gbcpu.Regs.a
 	hc := (((gbcpu.Regs.a & 0xf) - (operand & 0xf)) & 0x10) == 0x10
 	gbcpu.Regs.a = gbcpu.Regs.a - operand
@@ -983,7 +961,7 @@ func (gbcpu *GBCPU) SBCrr(reg1, reg2 *byte) {
 // Flags: Z1HC
 func (gbcpu *GBCPU) SBCAn() {
 	carry := gbcpu.Regs.getCarry()
-	operand := int(gbcpu.getOperands(1)[0])
+	operand := int(gbcpu.getOperand())
 	result := ((int(gbcpu.Regs.a)) - operand) - int(carry)
 
 	// Check for carry
@@ -1023,7 +1001,7 @@ func (gbcpu *GBCPU) SBCAn() {
 // Flags: Z1HC
 func (gbcpu *GBCPU) SBCAHL() {
 	carry := gbcpu.Regs.getCarry()
-	operand := GbMMU.Memory[gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)]
+	operand := gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
 	result := (int(gbcpu.Regs.a) - int(operand)) - int(carry)
 
 	if result < 0 {
@@ -1092,7 +1070,7 @@ func (gbcpu *GBCPU) CPr(reg *byte) {
 // Flags: Z1HC
 func (gbcpu *GBCPU) CPn() {
 
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 	oldVal := gbcpu.Regs.a
 	hc := (((gbcpu.Regs.a & 0xf) - (operand & 0xf)) & 0x10) == 0x10
 	sub := gbcpu.Regs.a - operand
@@ -1133,7 +1111,7 @@ func (gbcpu *GBCPU) CPn() {
 // Only updates flags
 // Flags: Z1HC
 func (gbcpu *GBCPU) CPaa(a1, a2 *byte) {
-	operand := GbMMU.Memory[binary.LittleEndian.Uint16([]byte{*a2, *a1})]
+	operand := gbcpu.read(gbcpu.Regs.JoinRegs(a1, a2))
 	oldVal := gbcpu.Regs.a
 	hc := (((gbcpu.Regs.a & 0xf) - (operand & 0xf)) & 0x10) == 0x10
 	sub := gbcpu.Regs.a - operand
@@ -1173,6 +1151,8 @@ func (gbcpu *GBCPU) CPaa(a1, a2 *byte) {
 // Copies reg1reg2 into addr (SP)
 // Flags: none
 func (gbcpu *GBCPU) PUSHrr(reg1, reg2 *byte) {
+	// SP is decremented in an internal cycle before the first write
+	gbcpu.tick(4)
 	gbcpu.pushByteToStack(*reg1)
 	gbcpu.pushByteToStack(*reg2)
 }
@@ -1194,65 +1174,61 @@ func (gbcpu *GBCPU) POPrr(reg1, reg2 *byte) {
 // Loads value at addr (a1a2) into reg
 // Flags: none
 func (gbcpu *GBCPU) LDraa(reg, a1, a2 *byte) {
-	*reg = GbMMU.ReadData(gbcpu.Regs.JoinRegs(a1, a2))
+	*reg = gbcpu.read(gbcpu.Regs.JoinRegs(a1, a2))
 }
 
 // LDaar -> e.g. LD (BC),A
 // Loads reg into value at addr (a1a2)
 // Flags: none
 func (gbcpu *GBCPU) LDaar(a1, a2, reg *byte) {
-	GbMMU.WriteData(gbcpu.Regs.JoinRegs(a1, a2), *reg)
+	gbcpu.write(gbcpu.Regs.JoinRegs(a1, a2), *reg)
 }
 
 // LDaaA -> e.g. LD (a16),A
 // Loads reg A into addr specified by next 2 bytes
 // Flags: none
 func (gbcpu *GBCPU) LDaaA(reg *byte) {
-	operands := gbcpu.getOperands(2)
-	operandsInt := gbcpu.sliceToInt(operands)
-	GbMMU.WriteData(operandsInt, *reg)
+	gbcpu.write(gbcpu.getOperandWord(), *reg)
 }
 
 // LDAaa -> e.g. LD A,(a16)
 // Loads value at addr specified by next 2 bytes into A
 // Flags: none
 func (gbcpu *GBCPU) LDAaa(reg *byte) {
-	operands := gbcpu.getOperands(2)
-	addr := gbcpu.sliceToInt(operands)
-	*reg = GbMMU.ReadData(addr)
+	*reg = gbcpu.read(gbcpu.getOperandWord())
 }
 
 // LDffCA -> e.g. LD ($FF00+C),A
 // Sets value at addr (0xFF00+C) to A
 func (gbcpu *GBCPU) LDffCA() {
-	GbMMU.WriteData(0xFF00+uint16(gbcpu.Regs.c), gbcpu.Regs.a)
+	gbcpu.write(0xFF00+uint16(gbcpu.Regs.c), gbcpu.Regs.a)
 }
 
 // LDAffC -> e.g. LD A,($FF00+C)
 // Sets A to value at addr (0xFF00+C)
 func (gbcpu *GBCPU) LDAffC() {
-	gbcpu.Regs.a = GbMMU.ReadData(0xFF00 + uint16(gbcpu.Regs.c))
+	gbcpu.Regs.a = gbcpu.read(0xFF00 + uint16(gbcpu.Regs.c))
 }
 
 // LDffnA -> e.g. LD ($FF00+a8),A
 // Loads A into value at addr ($FF00+a8)
 func (gbcpu *GBCPU) LDffnA() {
-	operand := gbcpu.getOperands(1)[0]
-	GbMMU.WriteData(0xFF00+uint16(operand), gbcpu.Regs.a)
+	operand := gbcpu.getOperand()
+	gbcpu.write(0xFF00+uint16(operand), gbcpu.Regs.a)
 }
 
 // LDAffn -> e.g. LD A,($FF00+a8)
 // Loads value at addr ($FF00+a8) into A
 func (gbcpu *GBCPU) LDAffn() {
-	operand := gbcpu.getOperands(1)[0]
-	gbcpu.Regs.a = GbMMU.ReadData(0xFF00 + uint16(operand))
+	operand := gbcpu.getOperand()
+	gbcpu.Regs.a = gbcpu.read(0xFF00 + uint16(operand))
 }
 
 // LDHLn -> e.g. LD (HL),i8
 // Loads 8 bit immediate into addr (HL)
 func (gbcpu *GBCPU) LDHLn() {
-	operand := gbcpu.getOperands(1)[0]
-	GbMMU.WriteData(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l), operand)
+	operand := gbcpu.getOperand()
+	gbcpu.write(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l), operand)
 }
 
 // LDDrHL -> e.g. LDD A,(HL)
@@ -1260,7 +1236,7 @@ func (gbcpu *GBCPU) LDHLn() {
 // Decrement HL
 func (gbcpu *GBCPU) LDDrHL(reg *byte) {
 	addr := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
-	*reg = GbMMU.Memory[addr]
+	*reg = gbcpu.read(addr)
 	gbcpu.Regs.h, gbcpu.Regs.l = gbcpu.Regs.SplitWord(addr - 1)
 }
 
@@ -1269,7 +1245,7 @@ func (gbcpu *GBCPU) LDDrHL(reg *byte) {
 // Decrement HL
 func (gbcpu *GBCPU) LDDHLr(reg *byte) {
 	addr := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
-	GbMMU.WriteData(addr, *reg)
+	gbcpu.write(addr, *reg)
 	gbcpu.Regs.h, gbcpu.Regs.l = gbcpu.Regs.SplitWord(addr - 1)
 }
 
@@ -1277,7 +1253,7 @@ func (gbcpu *GBCPU) LDDHLr(reg *byte) {
 // Set value at address a1a2 to value in reg
 // Increment reg
 func (gbcpu *GBCPU) LDIHLA() {
-	GbMMU.WriteData(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l), gbcpu.Regs.a)
+	gbcpu.write(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l), gbcpu.Regs.a)
 	hl := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
 	hl++
 	gbcpu.Regs.h, gbcpu.Regs.l = gbcpu.Regs.SplitWord(hl)
@@ -1287,7 +1263,7 @@ func (gbcpu *GBCPU) LDIHLA() {
 // Set value in reg to value at address a1a2
 // Increment HL
 func (gbcpu *GBCPU) LDIAHL() {
-	gbcpu.Regs.a = GbMMU.ReadData(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
+	gbcpu.Regs.a = gbcpu.read(gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l))
 	hl := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
 	hl++
 	gbcpu.Regs.h, gbcpu.Regs.l = gbcpu.Regs.SplitWord(hl)
@@ -1296,8 +1272,7 @@ func (gbcpu *GBCPU) LDIAHL() {
 // JPaa -> e.g. JP a16
 // Jumps to addr a16
 func (gbcpu *GBCPU) JPaa() {
-	jmpAddr := gbcpu.getOperands(2)
-	gbcpu.Regs.PC = jmpAddr
+	gbcpu.Regs.pc = gbcpu.getOperandWord()
 	gbcpu.Jumped = true
 }
 
@@ -1305,7 +1280,7 @@ func (gbcpu *GBCPU) JPaa() {
 // Jumps to addr specified by addr (HL)
 func (gbcpu *GBCPU) JPHL() {
 	addr := gbcpu.Regs.JoinRegs(&gbcpu.Regs.h, &gbcpu.Regs.l)
-	binary.LittleEndian.PutUint16(gbcpu.Regs.PC, addr)
+	gbcpu.Regs.pc = addr
 	gbcpu.Jumped = true
 }
 
@@ -1361,13 +1336,12 @@ func (gbcpu *GBCPU) JPNCaa() int {
 // Pushes the addr at PC+3 to the stack
 // Jumps to the address specified by next 2 bytes
 func (gbcpu *GBCPU) CALLaa() {
-	operands := gbcpu.getOperands(2)
-	nextInstr := gbcpu.sliceToInt(gbcpu.Regs.PC) + 3
-	nextInstrBytes := make([]byte, 2)
-	binary.LittleEndian.PutUint16(nextInstrBytes, nextInstr)
-	gbcpu.pushByteToStack(nextInstrBytes[1])
-	gbcpu.pushByteToStack(nextInstrBytes[0])
-	gbcpu.Regs.PC = operands
+	addr := gbcpu.getOperandWord()
+	nextInstr := gbcpu.Regs.pc + 3
+	// Internal cycle between reading the address and pushing
+	gbcpu.tick(4)
+	gbcpu.pushWordToStack(nextInstr)
+	gbcpu.Regs.pc = addr
 
 	gbcpu.Jumped = true
 }
@@ -1423,36 +1397,24 @@ func (gbcpu *GBCPU) CALLNCaa() int {
 // JRn -> e.g. JR s8
 // Add byte at PC + 1 to PC, and set PC to that value
 func (gbcpu *GBCPU) JRn() {
-	operand := gbcpu.getOperands(1)[0]
-	gbcpu.Regs.incrementPC(2)
+	operand := gbcpu.getOperand()
+	gbcpu.Regs.pc += 2
 
-	if operand > 127 {
-		location := 256 - uint16(operand)
-		newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) - uint16(location)
-		binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-	} else {
-		newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) + uint16(operand)
-		binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-	}
+	// The operand is signed
+	gbcpu.Regs.pc += uint16(int8(operand))
 	gbcpu.Jumped = true
 }
 
 // JRZn -> e.g. JR Z,s8
 // Performs JR to PC + s8 if Z is set
 func (gbcpu *GBCPU) JRZn() int {
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 
 	if gbcpu.Regs.getZero() != 0 {
-		gbcpu.Regs.incrementPC(2)
-
-		if operand > 127 {
-			location := 256 - uint16(operand)
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) - uint16(location)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		} else {
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) + uint16(operand)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		}
+		gbcpu.Regs.pc += 2
+
+		// The operand is signed
+		gbcpu.Regs.pc += uint16(int8(operand))
 		gbcpu.Jumped = true
 		return 4
 	}
@@ -1463,19 +1425,13 @@ func (gbcpu *GBCPU) JRZn() int {
 // JRNZn -> e.g. JR NZ,s8
 // Performs JR to PC + s8 if Z is not set
 func (gbcpu *GBCPU) JRNZn() int {
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 
 	if gbcpu.Regs.getZero() == 0 {
-		gbcpu.Regs.incrementPC(2)
-
-		if operand > 127 {
-			location := 256 - uint16(operand)
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) - uint16(location)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		} else {
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) + uint16(operand)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		}
+		gbcpu.Regs.pc += 2
+
+		// The operand is signed
+		gbcpu.Regs.pc += uint16(int8(operand))
 		gbcpu.Jumped = true
 		return 4
 	}
@@ -1486,19 +1442,13 @@ func (gbcpu *GBCPU) JRNZn() int {
 // JRCn -> e.g. JR C,s8
 // Performs JR to PC + s8 if C is set
 func (gbcpu *GBCPU) JRCn() int {
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 
 	if gbcpu.Regs.getCarry() != 0 {
-		gbcpu.Regs.incrementPC(2)
-
-		if operand > 127 {
-			location := 256 - uint16(operand)
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) - uint16(location)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		} else {
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) + uint16(operand)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		}
+		gbcpu.Regs.pc += 2
+
+		// The operand is signed
+		gbcpu.Regs.pc += uint16(int8(operand))
 		gbcpu.Jumped = true
 		return 4
 	}
@@ -1509,19 +1459,13 @@ func (gbcpu *GBCPU) JRCn() int {
 // JRNCn -> e.g. JR NC,s8
 // Performs JR to PC + s8 if C is not set
 func (gbcpu *GBCPU) JRNCn() int {
-	operand := gbcpu.getOperands(1)[0]
+	operand := gbcpu.getOperand()
 
 	if gbcpu.Regs.getCarry() == 0 {
-		gbcpu.Regs.incrementPC(2)
-
-		if operand > 127 {
-			location := 256 - uint16(operand)
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) - uint16(location)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		} else {
-			newPC := binary.LittleEndian.Uint16(gbcpu.Regs.PC) + uint16(operand)
-			binary.LittleEndian.PutUint16(gbcpu.Regs.PC, newPC)
-		}
+		gbcpu.Regs.pc += 2
+
+		// The operand is signed
+		gbcpu.Regs.pc += uint16(int8(operand))
 		gbcpu.Jumped = true
 		return 4
 	}
@@ -1602,9 +1546,7 @@ func (gbcpu *GBCPU) CPL() {
 
 // RET pops the top of the stack into the program counter
 func (gbcpu *GBCPU) RET() {
-	b1 := gbcpu.popByteFromStack()
-	b2 := gbcpu.popByteFromStack()
-	gbcpu.Regs.PC = []byte{b1, b2}
+	gbcpu.Regs.pc = gbcpu.popWordFromStack()
 	gbcpu.Jumped = true
 }
 
@@ -1616,6 +1558,9 @@ func (gbcpu *GBCPU) RETI() {
 
 // RETZ performs RET if Z is set
 func (gbcpu *GBCPU) RETZ() int {
+	// Condition is checked in an internal cycle before popping
+	gbcpu.tick(4)
+
 	if gbcpu.Regs.getZero() != 0 {
 		gbcpu.RET()
 		return 12
@@ -1626,6 +1571,9 @@ func (gbcpu *GBCPU) RETZ() int {
 
 // RETNZ performs RET if Z is not set
 func (gbcpu *GBCPU) RETNZ() int {
+	// Condition is checked in an internal cycle before popping
+	gbcpu.tick(4)
+
 	z := ((gbcpu.Regs.f >> 7) & 1)
 	if z == 0 {
 		gbcpu.RET()
@@ -1637,6 +1585,9 @@ func (gbcpu *GBCPU) RETNZ() int {
 
 // RETC performs RET if C is set
 func (gbcpu *GBCPU) RETC() int {
+	// Condition is checked in an internal cycle before popping
+	gbcpu.tick(4)
+
 	c := ((gbcpu.Regs.f >> 4) & 1)
 	if c != 0 {
 		gbcpu.RET()
@@ -1648,6 +1599,9 @@ func (gbcpu *GBCPU) RETC() int {
 
 // RETNC performs RET if C is not set
 func (gbcpu *GBCPU) RETNC() int {
+	// Condition is checked in an internal cycle before popping
+	gbcpu.tick(4)
+
 	c := ((gbcpu.Regs.f >> 4) & 1)
 	if c == 0 {
 		gbcpu.RET()
@@ -1669,32 +1623,53 @@ func (gbcpu *GBCPU) DI() {
 	gbcpu.EIReceived = false
 }
 
-// HALT stops the CPU until an interrupt occurs
+// HALT stops the CPU until an interrupt is pending, see Step
+// If IME is 0 and an interrupt is already pending, the CPU doesn't halt at
+// all. Instead it hits the HALT bug, where PC fails to increment after the
+// next opcode is fetched, so the byte after HALT is read twice
 func (gbcpu *GBCPU) HALT() {
-	// Save interrupt flag
-	gbcpu.IFPreHalt = GbMMU.ReadData(0xFF0F)
+	if gbcpu.IME == 0 && GbInterrupts.Pending() != 0 {
+		gbcpu.haltBug = true
+		return
+	}
 
-	// Halt CPU
 	gbcpu.Halted = true
 }
 
+// STOP resets the divider and puts the CPU into low power mode until a
+// button is pressed, see Step. The LCD and timer stop with it
+// On CGB, if a speed switch was armed through KEY1, STOP switches speed
+// instead and the CPU keeps running once the switch is done
+// Returns the extra cycles taken by a speed switch
+func (gbcpu *GBCPU) STOP() int {
+	mmu.ResetDivider()
+
+	if GbMMU.SpeedSwitchArmed() {
+		gbcpu.DoubleSpeed = GbMMU.SwitchSpeed()
+		return speedSwitchCycles
+	}
+
+	gbcpu.Stopped = true
+	return 0
+}
+
 // CB executes a CB-prefixed instruction
 func (gbcpu *GBCPU) CB() int {
-	operand := gbcpu.getOperands(1)[0]
-	gbcpu.InstrsCB[operand].Executor()
-	return int(gbcpu.InstrsCB[operand].TCycles)
+	operand := gbcpu.getOperand()
+	gbcpu.executorsCB[operand]()
+	return int(InstructionsCB[operand].TCycles)
 }
 
-func (gbcpu *GBCPU) sliceToInt(slice []byte) uint16 {
-	return binary.LittleEndian.Uint16(slice)
+// getOperand reads the 8-bit operand following the opcode
+// Operands are read through the MMU since they may live in a switchable ROM
+// bank
+func (gbcpu *GBCPU) getOperand() byte {
+	return gbcpu.read(gbcpu.Regs.pc + 1)
 }
 
-func (gbcpu *GBCPU) getOperands(number uint16) []byte {
-	begin := gbcpu.sliceToInt(gbcpu.Regs.PC) + 1
-	end := gbcpu.sliceToInt(gbcpu.Regs.PC) + (1 + number)
-
-	operands := make([]byte, 2)
-	copy(operands, GbMMU.Memory[begin:end])
-
-	return operands
+// getOperandWord reads the little endian 16-bit operand following the opcode
+func (gbcpu *GBCPU) getOperandWord() uint16 {
+	low := gbcpu.read(gbcpu.Regs.pc + 1)
+	high := gbcpu.read(gbcpu.Regs.pc + 2)
+	return uint16(high)<<8 | uint16(low)
 }
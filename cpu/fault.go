@@ -0,0 +1,72 @@
+package cpu
+
+import (
+	"fmt"
+)
+
+// Number of instructions remembered for fault diagnostics
+const historySize = 16
+
+// Executed is an instruction the CPU ran, kept for fault diagnostics
+type Executed struct {
+	PC     uint16
+	Opcode byte
+}
+
+// String returns the instruction's address and mnemonic
+func (executed Executed) String() string {
+	return fmt.Sprintf("$%04X  %02X  %s", executed.PC, executed.Opcode, Instructions[executed.Opcode].Mnemonic)
+}
+
+// Fault describes why the CPU locked up
+// The only cause is an illegal opcode, one of 0xD3, 0xDB, 0xDD, 0xE3, 0xE4,
+// 0xEB, 0xEC, 0xED, 0xF4, 0xFC and 0xFD
+type Fault struct {
+	// Address of the illegal opcode
+	PC     uint16
+	Opcode byte
+	// Instructions executed before the fault, oldest first
+	History []Executed
+}
+
+func (fault *Fault) Error() string {
+	return fmt.Sprintf("CPU: illegal opcode $%02X at $%04X, CPU locked up", fault.Opcode, fault.PC)
+}
+
+// recordHistory adds an instruction to the history ring buffer
+func (gbcpu *GBCPU) recordHistory(pc uint16, opcode byte) {
+	gbcpu.history[gbcpu.historyPos%historySize] = Executed{pc, opcode}
+	gbcpu.historyPos++
+}
+
+// recentHistory returns the instructions in the history ring buffer,
+// oldest first
+func (gbcpu *GBCPU) recentHistory() []Executed {
+	count := gbcpu.historyPos
+	if count > historySize {
+		count = historySize
+	}
+
+	history := make([]Executed, 0, count)
+	for i := gbcpu.historyPos - count; i < gbcpu.historyPos; i++ {
+		history = append(history, gbcpu.history[i%historySize])
+	}
+
+	return history
+}
+
+// lockUp is run instead of executing an illegal opcode
+// Hardware stops executing for good, ignoring interrupts, while the rest of
+// the system keeps running. Only a reset gets it going again
+func (gbcpu *GBCPU) lockUp(pc uint16, opcode byte) {
+	gbcpu.Locked = true
+	gbcpu.Fault = &Fault{
+		PC:      pc,
+		Opcode:  opcode,
+		History: gbcpu.recentHistory(),
+	}
+
+	if gbcpu.OnFault != nil {
+		gbcpu.OnFault(gbcpu.Fault)
+	}
+}
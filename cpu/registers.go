@@ -1,16 +1,17 @@
 package cpu
 
 import (
-	"encoding/binary"
 	"fmt"
+
+	"../cartridge"
 )
 
 // Registers represents Sharp LR35902 registers
 // Each individual register is a byte, but AF, BC, DE, HL can be addressed
 // as pairs (single 16-bit value)
-// Stack pointer and program counter are 2 bytes always
-// Could have also represented SP/PC as a single uint16. However, seems
-// more semantically accurate to do them as byte slices w/ 2 values
+// Stack pointer and program counter are native 16-bit values
+// Registers are read and set from outside the package through the accessors
+// at the bottom of this file, for debuggers, save states and tests
 // Notes:
 // Carry flag - https://stackoverflow.com/questions/31409444/what-is-the-behavior-of-the-carry-flag-for-cp-on-a-game-boy
 // Half carry flag - http://stackoverflow.com/questions/8868396/gbz80-what-constitutes-a-half-carry/8874607#8874607
@@ -30,19 +31,39 @@ type Registers struct {
 	h byte
 	l byte
 
-	sp []byte // Stack pointer
-	PC []byte // Program counter
+	sp uint16 // Stack pointer
+	pc uint16 // Program counter
 }
 
 // InitRegs sets post-bootrom register values
+// These differ between models, and games check A to detect a CGB
 // GB register initial values:
 // http://bgb.bircd.org/pandocs.htm#powerupsequence
-func (regs *Registers) InitRegs() {
-	regs.a, regs.f = 0x01, 0xB0
-	regs.b, regs.c = 0x00, 0x13
-	regs.d, regs.e = 0x00, 0xD8
-	regs.h, regs.l = 0x01, 0x4D
-	regs.sp = []byte{0xFF, 0xFE}
+func (regs *Registers) InitRegs(model cartridge.Model) {
+	if model == cartridge.CGB {
+		regs.a, regs.f = 0x11, 0x80
+		regs.b, regs.c = 0x00, 0x00
+		regs.d, regs.e = 0xFF, 0x56
+		regs.h, regs.l = 0x00, 0x0D
+	} else {
+		regs.a, regs.f = 0x01, 0xB0
+		regs.b, regs.c = 0x00, 0x13
+		regs.d, regs.e = 0x00, 0xD8
+		regs.h, regs.l = 0x01, 0x4D
+	}
+	regs.sp = 0xFFFE
+}
+
+// InitPowerOn sets register values for starting at the boot ROM
+// Registers start cleared and PC starts at 0x0000, the boot ROM sets up
+// everything else before jumping to the cartridge
+func (regs *Registers) InitPowerOn() {
+	regs.a, regs.f = 0x00, 0x00
+	regs.b, regs.c = 0x00, 0x00
+	regs.d, regs.e = 0x00, 0x00
+	regs.h, regs.l = 0x00, 0x00
+	regs.sp = 0x0000
+	regs.pc = 0x0000
 }
 
 // SplitWord splits a 16 bit integer into 2 bytes
@@ -64,30 +85,6 @@ func (regs *Registers) JoinRegs(reg1, reg2 *byte) uint16 {
 	return result
 }
 
-// incrementSP converts current SP to an integer,
-// increments it by amt, then stores it back as 2 bytes
-func (regs *Registers) incrementSP(amt byte) {
-	spInt := binary.LittleEndian.Uint16(regs.sp)
-	spInt += uint16(amt)
-	binary.LittleEndian.PutUint16(regs.sp, spInt)
-}
-
-// decrementSP converts current SP to an integer,
-// decrements it by amt, then stores it back as 2 bytes
-func (regs *Registers) decrementSP(amt byte) {
-	spInt := binary.LittleEndian.Uint16(regs.sp)
-	spInt -= uint16(amt)
-	binary.LittleEndian.PutUint16(regs.sp, spInt)
-}
-
-// incrementPC converts current PC to an integer,
-// increments it by amt, then stores it back as 2 bytes
-func (regs *Registers) incrementPC(amt uint16) {
-	pcInt := binary.LittleEndian.Uint16(regs.PC)
-	pcInt += amt
-	binary.LittleEndian.PutUint16(regs.PC, pcInt)
-}
-
 // setZero sets the 7th bit of register F
 func (regs *Registers) setZero() {
 	regs.f |= (1 << 7)
@@ -171,12 +168,152 @@ func (regs *Registers) getCarry() byte {
 	return 0
 }
 
+// String formats register values the way Gameboy Doctor logs them
+// Reference: https://github.com/robert/gameboy-doctor
+func (regs *Registers) String() string {
+	return fmt.Sprintf("A:%02X F:%02X B:%02X C:%02X D:%02X E:%02X H:%02X L:%02X SP:%04X PC:%04X",
+		regs.a, regs.f, regs.b, regs.c, regs.d, regs.e, regs.h, regs.l,
+		regs.sp, regs.pc)
+}
+
 // Dump prints register values for debugging
 func (regs *Registers) Dump() {
-	fmt.Printf("AF: %02X %02X\n", regs.a, regs.f)
-	fmt.Printf("BC: %02X %02X\n", regs.b, regs.c)
-	fmt.Printf("DE: %02X %02X\n", regs.d, regs.e)
-	fmt.Printf("HL: %02X %02X\n", regs.h, regs.l)
-	fmt.Printf("SP: %04X\n", regs.sp)
-	fmt.Printf("PC: %04X\n", regs.PC)
+	fmt.Println(regs)
+}
+
+// Flag bits in F
+const (
+	FlagZ byte = 1 << 7 // Zero
+	FlagN byte = 1 << 6 // Subtract
+	FlagH byte = 1 << 5 // Half carry
+	FlagC byte = 1 << 4 // Carry
+)
+
+// A returns the accumulator
+func (regs *Registers) A() byte { return regs.a }
+
+// F returns the flags register
+func (regs *Registers) F() byte { return regs.f }
+
+// B returns register B
+func (regs *Registers) B() byte { return regs.b }
+
+// C returns register C
+func (regs *Registers) C() byte { return regs.c }
+
+// D returns register D
+func (regs *Registers) D() byte { return regs.d }
+
+// E returns register E
+func (regs *Registers) E() byte { return regs.e }
+
+// H returns register H
+func (regs *Registers) H() byte { return regs.h }
+
+// L returns register L
+func (regs *Registers) L() byte { return regs.l }
+
+// SetA sets the accumulator
+func (regs *Registers) SetA(value byte) {
+	regs.a = value
+}
+
+// SetF sets the flags register
+// The lower 4 bits of F always read 0, so they're cleared
+func (regs *Registers) SetF(value byte) {
+	regs.f = value & 0xF0
+}
+
+// SetB sets register B
+func (regs *Registers) SetB(value byte) {
+	regs.b = value
+}
+
+// SetC sets register C
+func (regs *Registers) SetC(value byte) {
+	regs.c = value
+}
+
+// SetD sets register D
+func (regs *Registers) SetD(value byte) {
+	regs.d = value
+}
+
+// SetE sets register E
+func (regs *Registers) SetE(value byte) {
+	regs.e = value
+}
+
+// SetH sets register H
+func (regs *Registers) SetH(value byte) {
+	regs.h = value
+}
+
+// SetL sets register L
+func (regs *Registers) SetL(value byte) {
+	regs.l = value
+}
+
+// AF returns registers A and F as a pair
+func (regs *Registers) AF() uint16 { return regs.JoinRegs(&regs.a, &regs.f) }
+
+// BC returns registers B and C as a pair
+func (regs *Registers) BC() uint16 { return regs.JoinRegs(&regs.b, &regs.c) }
+
+// DE returns registers D and E as a pair
+func (regs *Registers) DE() uint16 { return regs.JoinRegs(&regs.d, &regs.e) }
+
+// HL returns registers H and L as a pair
+func (regs *Registers) HL() uint16 { return regs.JoinRegs(&regs.h, &regs.l) }
+
+// SP returns the stack pointer
+func (regs *Registers) SP() uint16 { return regs.sp }
+
+// PC returns the program counter
+func (regs *Registers) PC() uint16 { return regs.pc }
+
+// SetAF sets registers A and F as a pair, see SetF
+func (regs *Registers) SetAF(value uint16) {
+	regs.a = byte(value >> 8)
+	regs.SetF(byte(value))
+}
+
+// SetBC sets registers B and C as a pair
+func (regs *Registers) SetBC(value uint16) {
+	regs.b, regs.c = regs.SplitWord(value)
+}
+
+// SetDE sets registers D and E as a pair
+func (regs *Registers) SetDE(value uint16) {
+	regs.d, regs.e = regs.SplitWord(value)
+}
+
+// SetHL sets registers H and L as a pair
+func (regs *Registers) SetHL(value uint16) {
+	regs.h, regs.l = regs.SplitWord(value)
+}
+
+// SetSP sets the stack pointer
+func (regs *Registers) SetSP(value uint16) {
+	regs.sp = value
+}
+
+// SetPC sets the program counter
+func (regs *Registers) SetPC(value uint16) {
+	regs.pc = value
+}
+
+// Flag returns true if flag is set in F
+// flag is one of FlagZ, FlagN, FlagH or FlagC
+func (regs *Registers) Flag(flag byte) bool {
+	return regs.f&flag != 0
+}
+
+// SetFlag sets or clears flag in F
+func (regs *Registers) SetFlag(flag byte, set bool) {
+	if set {
+		regs.f |= flag
+	} else {
+		regs.f &^= flag
+	}
 }
@@ -0,0 +1,90 @@
+package cpu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// AnyBank turns off a Tracer's ROM bank filter
+const AnyBank = -1
+
+// Tracer logs a line for every instruction the CPU executes, in the format
+// used by Gameboy Doctor, so runs can be diffed against other emulators
+// Each line has the registers and the 4 bytes at PC, before the instruction
+// at PC executes. Interrupt dispatch isn't logged, only the handler's
+// instructions are
+// Reference: https://github.com/robert/gameboy-doctor
+type Tracer struct {
+	// Toggles tracing at runtime
+	Enabled bool
+	// Only instructions with From <= PC <= To are traced
+	From, To uint16
+	// Only instructions in this ROM bank are traced, unless it's AnyBank
+	// Code running from RAM isn't in any bank, so it's left out too
+	Bank int
+
+	out *bufio.Writer
+	// Most recent lines, nil when lines are written to out as they happen
+	ring    []string
+	ringPos int
+}
+
+// NewTracer returns an enabled Tracer that traces every instruction to out
+// If ringSize is above 0, only the last ringSize lines are kept, and they
+// aren't written to out until Flush is called
+func NewTracer(out io.Writer, ringSize int) *Tracer {
+	tracer := &Tracer{
+		Enabled: true,
+		From:    0x0000,
+		To:      0xFFFF,
+		Bank:    AnyBank,
+		out:     bufio.NewWriter(out),
+	}
+
+	if ringSize > 0 {
+		tracer.ring = make([]string, 0, ringSize)
+	}
+
+	return tracer
+}
+
+// trace logs the instruction at pc if it passes the filters
+func (tracer *Tracer) trace(gbcpu *GBCPU, pc uint16) {
+	if pc < tracer.From || pc > tracer.To {
+		return
+	}
+	if tracer.Bank != AnyBank && GbMMU.ROMBank(pc) != tracer.Bank {
+		return
+	}
+
+	line := fmt.Sprintf("%s PCMEM:%02X,%02X,%02X,%02X\n", gbcpu.Regs,
+		GbBus.ReadData(pc), GbBus.ReadData(pc+1), GbBus.ReadData(pc+2), GbBus.ReadData(pc+3))
+
+	if tracer.ring == nil {
+		tracer.out.WriteString(line)
+		return
+	}
+
+	if len(tracer.ring) < cap(tracer.ring) {
+		tracer.ring = append(tracer.ring, line)
+	} else {
+		tracer.ring[tracer.ringPos] = line
+		tracer.ringPos = (tracer.ringPos + 1) % len(tracer.ring)
+	}
+}
+
+// Flush writes any buffered lines to out
+// The ring buffer is written oldest line first, then emptied
+func (tracer *Tracer) Flush() error {
+	for i := range tracer.ring {
+		tracer.out.WriteString(tracer.ring[(tracer.ringPos+i)%len(tracer.ring)])
+	}
+
+	if tracer.ring != nil {
+		tracer.ring = tracer.ring[:0]
+		tracer.ringPos = 0
+	}
+
+	return tracer.out.Flush()
+}
@@ -0,0 +1,126 @@
+package cpu
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"../mmu"
+)
+
+// tracedPCs returns the PC of each line traced
+func tracedPCs(trace string) []string {
+	var pcs []string
+	for _, line := range strings.Split(strings.TrimSpace(trace), "\n") {
+		if line == "" {
+			continue
+		}
+
+		start := strings.Index(line, "PC:") + len("PC:")
+		pcs = append(pcs, line[start:start+4])
+	}
+
+	return pcs
+}
+
+// The ring buffer keeps only the most recent lines, and writes them oldest
+// first when flushed
+func TestTracerRing(t *testing.T) {
+	gbcpu, _ := newTestCPU(make([]byte, 8))
+	out := new(bytes.Buffer)
+	gbcpu.Tracer = NewTracer(out, 3)
+
+	for i := 0; i < 5; i++ {
+		gbcpu.Step()
+	}
+	if out.Len() != 0 {
+		t.Errorf("ring buffer wrote %q before Flush", out.String())
+	}
+
+	err := gbcpu.Tracer.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "0102 0103 0104"
+	if got := strings.Join(tracedPCs(out.String()), " "); got != expected {
+		t.Errorf("traced PCs %s, expected %s", got, expected)
+	}
+	if !strings.HasPrefix(out.String(), "A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0102 PCMEM:00,00,00,00\n") {
+		t.Errorf("trace doesn't start with the line for $0102, got %q", out.String())
+	}
+
+	// Flushing empties the ring, so only newer lines are written next time
+	gbcpu.Step()
+	out.Reset()
+	gbcpu.Tracer.Flush()
+	if got := strings.Join(tracedPCs(out.String()), " "); got != "0105" {
+		t.Errorf("traced PCs %s after flushing again, expected 0105", got)
+	}
+}
+
+// bankedCart writes an MBC1 cartridge that switches to bank 2, runs 2 NOPs
+// there, then runs from WRAM
+// Returns the path to the cartridge
+func bankedCart(t *testing.T, dir string) string {
+	rom := make([]byte, 4*0x4000)
+	copy(rom[0x0100:], []byte{
+		0x3E, 0x02, // 0100: LD A,$02
+		0xEA, 0x00, 0x20, // 0102: LD ($2000),A
+		0xC3, 0x00, 0x40, // 0105: JP $4000
+	})
+	rom[0x0147] = 0x01 // MBC1
+	rom[0x0148] = 0x01 // 64KB
+
+	copy(rom[2*0x4000:], []byte{
+		0x00,             // 4000: NOP
+		0x00,             // 4001: NOP
+		0xC3, 0x00, 0xC0, // 4002: JP $C000
+	})
+
+	path := filepath.Join(dir, "banked.gb")
+	err := ioutil.WriteFile(path, rom, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// Only instructions in the filtered ROM bank are traced, and code running
+// from RAM isn't in any bank
+func TestTracerBankFilter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "halken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	gbcpu, _ := newTestCPU(nil)
+	GbMMU = new(mmu.GBMMU)
+	GbMMU.InitMMU()
+	GbMMU.DisableSaves = true
+	err = GbMMU.LoadCart(bankedCart(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	GbBus = GbMMU
+
+	out := new(bytes.Buffer)
+	gbcpu.Tracer = NewTracer(out, 0)
+	gbcpu.Tracer.Bank = 2
+
+	// 3 instructions in bank 0, 3 in bank 2, then NOPs in WRAM
+	for i := 0; i < 9; i++ {
+		gbcpu.Step()
+	}
+	gbcpu.Tracer.Flush()
+
+	expected := "4000 4001 4002"
+	if got := strings.Join(tracedPCs(out.String()), " "); got != expected {
+		t.Errorf("traced PCs %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+// The cputest command runs the CPU against a directory of single step test
+// vectors, e.g. halken cputest sm83/v1
+// See cputest/cputest.go for the format
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"./cputest"
+)
+
+// runCPUTest runs the cputest command with its arguments
+// Returns 0 if every test passed, 1 otherwise
+func runCPUTest(args []string) int {
+	flags := flag.NewFlagSet("cputest", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: halken cputest [flags] /path/to/vectors\n")
+		flags.PrintDefaults()
+	}
+	verbose := flags.Bool("v", false, "also list opcodes that passed")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	results, err := cputest.Run(flags.Arg(0))
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+
+	failedOpcodes := 0
+	for _, result := range results {
+		if result.Failed == 0 {
+			if *verbose {
+				fmt.Printf("%s: passed %d tests\n", result.Opcode, result.Total)
+			}
+			continue
+		}
+
+		failedOpcodes++
+		fmt.Printf("%s: failed %d of %d tests, first was %q:\n", result.Opcode, result.Failed, result.Total, result.FirstFailure)
+		for _, problem := range result.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+
+	fmt.Printf("%d of %d opcodes passed\n", len(results)-failedOpcodes, len(results))
+	if failedOpcodes > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,396 @@
+// Package cputest runs the CPU against single step test vectors, which
+// describe the state before and after executing a single instruction
+// The vectors use the format of the SM83 single step tests, one JSON file per
+// opcode, each holding an array of tests like:
+//
+//	{
+//	  "name": "00 0000",
+//	  "initial": {"pc": 49152, "sp": 0, "a": 0, "f": 0, ..., "ime": 0,
+//	              "ram": [[49152, 0]]},
+//	  "final": {...},
+//	  "cycles": [[49152, 0, "r-m"]]
+//	}
+//
+// Reference: https://github.com/SingleStepTests/sm83
+// The CPU runs alone against a flat 64KB bus, so nothing like the MBC or I/O
+// registers gets in the way
+// Each entry in cycles is an M-cycle, giving the address and data on the bus
+// and whether it was read or written, or null if the bus was idle. The bus
+// records every access, and they're compared in order against the cycles
+// that aren't idle. Idle cycles only count towards the total
+//
+// Vectors come in two conventions. Either the opcode is at pc and its fetch
+// is the first cycle, or, like the SM83 vectors, the opcode was already
+// fetched during the previous instruction and sits at pc-1. The last cycle
+// then fetches the next opcode at the final pc-1, overlapping the next
+// instruction's execution like the hardware does. Each file's convention is
+// detected from where the opcode in its name is found, see detectOverlap
+package cputest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"../cartridge"
+	"../cpu"
+	"../interrupts"
+	"../mmu"
+)
+
+// vector is a single test
+type vector struct {
+	Name    string
+	Initial state
+	Final   state
+	// One entry per M-cycle
+	Cycles []busCycle
+}
+
+// state is the CPU and memory before or after a test
+type state struct {
+	PC, SP                 uint16
+	A, F, B, C, D, E, H, L byte
+	IME                    byte
+	// Address and value pairs
+	RAM [][2]uint16
+}
+
+// Result is the outcome of the tests for one opcode
+type Result struct {
+	// Name of the test file, like "cb 7e"
+	Opcode string
+	Total  int
+	Failed int
+	// Differences found in the first failing test
+	FirstFailure string
+	Problems     []string
+}
+
+// busCycle is what happened on the bus during an M-cycle
+type busCycle struct {
+	addr  uint16
+	data  byte
+	read  bool
+	write bool
+}
+
+// UnmarshalJSON decodes a cycle like [49152, 0, "r-m"]
+// The activity string has r in its first place for a read, and w in its
+// second for a write. A null cycle or "---" means the bus was idle
+func (cycle *busCycle) UnmarshalJSON(data []byte) error {
+	var fields []interface{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	if fields == nil {
+		return nil
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("cputest: bus cycle %s should have 3 fields", data)
+	}
+
+	// Data can be null for idle cycles too
+	addr, _ := fields[0].(float64)
+	value, _ := fields[1].(float64)
+	activity, _ := fields[2].(string)
+
+	cycle.addr = uint16(addr)
+	cycle.data = byte(value)
+	cycle.read = strings.HasPrefix(activity, "r")
+	cycle.write = len(activity) > 1 && activity[1] == 'w'
+
+	return nil
+}
+
+// idle returns true if the bus wasn't accessed during the cycle
+func (cycle busCycle) idle() bool {
+	return !cycle.read && !cycle.write
+}
+
+func (cycle busCycle) String() string {
+	if cycle.write {
+		return fmt.Sprintf("write $%02X to $%04X", cycle.data, cycle.addr)
+	}
+
+	return fmt.Sprintf("read $%02X from $%04X", cycle.data, cycle.addr)
+}
+
+// flatBus is 64KB of RAM, with no special cases at all
+// Every access is recorded, to be checked against the test's cycles
+type flatBus struct {
+	memory   [65536]byte
+	accesses []busCycle
+}
+
+func (bus *flatBus) ReadData(addr uint16) byte {
+	data := bus.memory[addr]
+	bus.accesses = append(bus.accesses, busCycle{addr: addr, data: data, read: true})
+	return data
+}
+
+func (bus *flatBus) WriteData(addr uint16, data byte) {
+	bus.memory[addr] = data
+	bus.accesses = append(bus.accesses, busCycle{addr: addr, data: data, write: true})
+}
+
+// Run runs every JSON file of test vectors in dir
+// Returns a result for each file, sorted by opcode
+func Run(dir string) ([]Result, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("cputest: %s", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("cputest: no test vectors in %s", dir)
+	}
+	sort.Strings(paths)
+
+	bus := new(flatBus)
+	gbcpu := setup(bus)
+
+	var results []Result
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cputest: %s", err)
+		}
+
+		var vectors []vector
+		err = json.Unmarshal(data, &vectors)
+		if err != nil {
+			return nil, fmt.Errorf("cputest: %s: %s", path, err)
+		}
+
+		name := filepath.Base(path)
+		result := Result{Opcode: name[:len(name)-len(".json")]}
+
+		overlap, err := detectOverlap(result.Opcode, vectors)
+		if err != nil {
+			return nil, fmt.Errorf("cputest: %s: %s", path, err)
+		}
+
+		for _, test := range vectors {
+			result.Total++
+
+			problems := runVector(gbcpu, bus, test, overlap)
+			if len(problems) == 0 {
+				continue
+			}
+
+			result.Failed++
+			if result.FirstFailure == "" {
+				result.FirstFailure = test.Name
+				result.Problems = problems
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// setup creates a CPU whose memory accesses go to bus
+// Other components are replaced with ones that do nothing, and no interrupt
+// is ever pending
+func setup(bus *flatBus) *cpu.GBCPU {
+	cpu.GbBus = bus
+	cpu.GbMMU = new(mmu.GBMMU)
+	cpu.GbInterrupts = new(interrupts.GBInterrupts)
+	cpu.Tick = nil
+	mmu.ResetDivider = func() {}
+
+	gbcpu := new(cpu.GBCPU)
+	gbcpu.InitCPU(cartridge.DMG)
+
+	return gbcpu
+}
+
+// detectOverlap returns true if vectors use the overlapped fetch convention,
+// where the opcode is at pc-1 rather than at pc
+// opcode is the name of the vectors' file, like "3e" or "cb 7e"
+// Every vector in a file uses the same convention, so it's only overlapped if
+// no vector has the opcode at pc, and every vector has it at pc-1
+func detectOverlap(opcode string, vectors []vector) (bool, error) {
+	var op []byte
+	for _, field := range strings.Fields(opcode) {
+		value, err := strconv.ParseUint(field, 16, 8)
+		if err != nil {
+			return false, fmt.Errorf("can't tell the opcode from the file name %q", opcode)
+		}
+		op = append(op, byte(value))
+	}
+	if len(op) == 0 {
+		return false, fmt.Errorf("can't tell the opcode from the file name %q", opcode)
+	}
+
+	atPC, atPrevPC := 0, 0
+	for _, test := range vectors {
+		memory := make(map[uint16]byte)
+		for _, entry := range test.Initial.RAM {
+			memory[entry[0]] = byte(entry[1])
+		}
+
+		found := func(addr uint16) bool {
+			for i, data := range op {
+				value, ok := memory[addr+uint16(i)]
+				if !ok || value != data {
+					return false
+				}
+			}
+			return true
+		}
+
+		if found(test.Initial.PC) {
+			atPC++
+		}
+		if found(test.Initial.PC - 1) {
+			atPrevPC++
+		}
+	}
+
+	switch {
+	case atPC == len(vectors):
+		return false, nil
+	case atPrevPC == len(vectors):
+		return true, nil
+	default:
+		return false, fmt.Errorf("opcode %s is at pc in %d vectors and at pc-1 in %d, out of %d", opcode, atPC, atPrevPC, len(vectors))
+	}
+}
+
+// runVector executes the instruction described by test
+// If overlap is set, the opcode is at pc-1 and the next opcode is fetched
+// after executing it, see the package comment
+// Returns a description of each way the outcome differs from the test
+func runVector(gbcpu *cpu.GBCPU, bus *flatBus, test vector, overlap bool) []string {
+	for _, entry := range test.Initial.RAM {
+		bus.memory[entry[0]] = byte(entry[1])
+	}
+
+	regs := gbcpu.Regs
+	initial := test.Initial
+	regs.SetA(initial.A)
+	regs.SetF(initial.F)
+	regs.SetB(initial.B)
+	regs.SetC(initial.C)
+	regs.SetD(initial.D)
+	regs.SetE(initial.E)
+	regs.SetH(initial.H)
+	regs.SetL(initial.L)
+	regs.SetSP(initial.SP)
+	regs.SetPC(initial.PC)
+	if overlap {
+		regs.SetPC(initial.PC - 1)
+	}
+	gbcpu.IME = initial.IME
+	gbcpu.EIReceived = false
+	gbcpu.Halted = false
+	gbcpu.Stopped = false
+	gbcpu.Locked = false
+
+	bus.accesses = bus.accesses[:0]
+	cycles := gbcpu.Step()
+
+	// The opcode's fetch belongs to the previous instruction, and the next
+	// opcode's fetch to this one. The fetch doesn't execute anything, so it
+	// only moves PC past the next opcode
+	if overlap && len(bus.accesses) > 0 {
+		bus.accesses = bus.accesses[1:]
+		bus.ReadData(regs.PC())
+		regs.SetPC(regs.PC() + 1)
+	}
+
+	var problems []string
+	check := func(name string, got, expected uint16) {
+		if got != expected {
+			problems = append(problems, fmt.Sprintf("%s is %02X, expected %02X", name, got, expected))
+		}
+	}
+
+	final := test.Final
+	check("A", uint16(regs.A()), uint16(final.A))
+	if regs.F() != final.F {
+		problems = append(problems, fmt.Sprintf("F is %s, expected %s", flags(regs.F()), flags(final.F)))
+	}
+	check("B", uint16(regs.B()), uint16(final.B))
+	check("C", uint16(regs.C()), uint16(final.C))
+	check("D", uint16(regs.D()), uint16(final.D))
+	check("E", uint16(regs.E()), uint16(final.E))
+	check("H", uint16(regs.H()), uint16(final.H))
+	check("L", uint16(regs.L()), uint16(final.L))
+	check("SP", regs.SP(), final.SP)
+	check("PC", regs.PC(), final.PC)
+
+	// EI only sets IME after the next instruction, so EI having been
+	// received counts as IME being set
+	ime := gbcpu.IME
+	if gbcpu.EIReceived {
+		ime = 1
+	}
+	check("IME", uint16(ime), uint16(final.IME))
+
+	for _, entry := range final.RAM {
+		check(fmt.Sprintf("($%04X)", entry[0]), uint16(bus.memory[entry[0]]), entry[1])
+	}
+
+	if cycles != 4*len(test.Cycles) {
+		problems = append(problems, fmt.Sprintf("took %d cycles, expected %d", cycles, 4*len(test.Cycles)))
+	}
+	problems = append(problems, checkAccesses(bus.accesses, test.Cycles)...)
+
+	// Leave memory clean for the next test
+	for _, entry := range test.Initial.RAM {
+		bus.memory[entry[0]] = 0
+	}
+	for _, entry := range final.RAM {
+		bus.memory[entry[0]] = 0
+	}
+
+	return problems
+}
+
+// checkAccesses compares the bus accesses made by an instruction with the
+// cycles of its test, in order
+// Returns a description of the first access that differs, and of any
+// accesses that are missing or extra
+func checkAccesses(accesses, cycles []busCycle) []string {
+	var expected []busCycle
+	for _, cycle := range cycles {
+		if !cycle.idle() {
+			expected = append(expected, cycle)
+		}
+	}
+
+	var problems []string
+	for i := 0; i < len(accesses) && i < len(expected); i++ {
+		if accesses[i] != expected[i] {
+			problems = append(problems, fmt.Sprintf("bus access %d was %s, expected %s", i+1, accesses[i], expected[i]))
+			break
+		}
+	}
+
+	if len(accesses) != len(expected) {
+		problems = append(problems, fmt.Sprintf("made %d bus accesses, expected %d", len(accesses), len(expected)))
+	}
+
+	return problems
+}
+
+// flags formats F as ZNHC, with - for cleared flags
+func flags(f byte) string {
+	names := []byte("ZNHC")
+	for i := range names {
+		if f&(0x80>>uint(i)) == 0 {
+			names[i] = '-'
+		}
+	}
+
+	return string(names)
+}
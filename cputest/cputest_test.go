@@ -0,0 +1,92 @@
+package cputest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testdata has the opcode at pc, and testdata/overlapped has it at pc-1 with
+// the next opcode fetched at the end, like the SM83 vectors
+func TestVectors(t *testing.T) {
+	for _, dir := range []string{"testdata", "testdata/overlapped"} {
+		results, err := Run(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, result := range results {
+			if result.Failed != 0 {
+				t.Errorf("%s/%s: failed %d of %d tests, first was %q: %s", dir, result.Opcode, result.Failed, result.Total,
+					result.FirstFailure, strings.Join(result.Problems, ", "))
+			}
+		}
+	}
+}
+
+// ldVector returns a vector for LD A,i8 with the given bytes at 0xC000 and
+// pc, leaving out the final state
+func ldVector(pc uint16, ram ...byte) vector {
+	test := vector{Name: "3e", Initial: state{PC: pc}}
+	for i, data := range ram {
+		test.Initial.RAM = append(test.Initial.RAM, [2]uint16{0xC000 + uint16(i), uint16(data)})
+	}
+
+	return test
+}
+
+func TestDetectOverlap(t *testing.T) {
+	tests := []struct {
+		name    string
+		opcode  string
+		vectors []vector
+		overlap bool
+		err     bool
+	}{
+		{"opcode at pc", "3e", []vector{ldVector(0xC000, 0x3E, 0x3E), ldVector(0xC000, 0x3E, 0x01)}, false, false},
+		{"opcode at pc-1", "3e", []vector{ldVector(0xC001, 0x3E, 0x3E), ldVector(0xC001, 0x3E, 0x01)}, true, false},
+		{"CB opcode at pc-1", "cb 37", []vector{ldVector(0xC001, 0xCB, 0x37, 0xCB)}, true, false},
+		{"CB operand doesn't match", "cb 37", []vector{ldVector(0xC000, 0xCB, 0x38)}, false, true},
+		{"mixed", "3e", []vector{ldVector(0xC000, 0x3E, 0x01), ldVector(0xC001, 0x3E, 0x01)}, false, true},
+		{"bad file name", "xyz", []vector{ldVector(0xC000, 0x3E)}, false, true},
+	}
+
+	for _, test := range tests {
+		overlap, err := detectOverlap(test.opcode, test.vectors)
+		if (err != nil) != test.err {
+			t.Errorf("%s: error is %v, expected an error to be %t", test.name, err, test.err)
+			continue
+		}
+		if err == nil && overlap != test.overlap {
+			t.Errorf("%s: overlap is %t, expected %t", test.name, overlap, test.overlap)
+		}
+	}
+}
+
+// CALL pushes the upper byte of the return address first, so a vector
+// expecting the lower byte first has to fail on its bus cycles alone
+func TestBusCycleMismatch(t *testing.T) {
+	data := `{
+		"name": "cd swapped",
+		"initial": {"pc": 49152, "sp": 53248, "a": 0, "f": 0, "b": 0, "c": 0, "d": 0, "e": 0, "h": 0, "l": 0, "ime": 0,
+			"ram": [[49152, 205], [49153, 52], [49154, 18]]},
+		"final": {"pc": 4660, "sp": 53246, "a": 0, "f": 0, "b": 0, "c": 0, "d": 0, "e": 0, "h": 0, "l": 0, "ime": 0,
+			"ram": [[53246, 3], [53247, 192]]},
+		"cycles": [[49152, 205, "r-m"], [49153, 52, "r-m"], [49154, 18, "r-m"], null,
+			[53246, 3, "-wm"], [53247, 192, "-wm"]]
+	}`
+
+	var test vector
+	err := json.Unmarshal([]byte(data), &test)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := new(flatBus)
+	problems := runVector(setup(bus), bus, test, false)
+
+	expected := "bus access 4 was write $C0 to $CFFF, expected write $03 to $CFFE"
+	if len(problems) != 1 || problems[0] != expected {
+		t.Errorf("problems were %q, expected only %q", problems, expected)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+// The disasm command prints the disassembly of part of a ROM without running
+// it, e.g. halken disasm rom.gb --bank 2 --from 4000
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"./disasm"
+)
+
+// runDisasm runs the disasm command with its arguments, which are the ROM's
+// path and flags in any order
+// Returns the exit code
+func runDisasm(args []string) int {
+	flags := flag.NewFlagSet("disasm", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: halken disasm /path/to/rom [flags]\n")
+		flags.PrintDefaults()
+	}
+	bank := flags.Int("bank", 1, "ROM bank mapped at 4000-7FFF")
+	from := flags.String("from", "0100", "hex `address` to start disassembling at")
+	to := flags.String("to", "", "hex `address` to stop disassembling at, instead of -count")
+	count := flags.Int("count", 32, "number of instructions to disassemble")
+
+	// flag stops parsing at the first non-flag argument, so the ROM's path
+	// is picked out and parsing carries on after it
+	var paths []string
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return 2
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		paths = append(paths, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(paths) != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	fromAddr, err := parseAddr(*from)
+	if err != nil {
+		fmt.Printf("disasm: %s\n", err)
+		return 2
+	}
+
+	data, err := ioutil.ReadFile(paths[0])
+	if err != nil {
+		fmt.Printf("disasm: %s\n", err)
+		return 1
+	}
+
+	rom, err := disasm.NewROM(data, *bank)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+
+	var lines []disasm.Line
+	if *to != "" {
+		toAddr, err := parseAddr(*to)
+		if err != nil {
+			fmt.Printf("disasm: %s\n", err)
+			return 2
+		}
+		lines = disasm.Range(rom, fromAddr, toAddr)
+	} else {
+		lines = disasm.Count(rom, fromAddr, *count)
+	}
+
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	return 0
+}
+
+// parseAddr parses a hex address, optionally prefixed with $ or 0x
+func parseAddr(addr string) (uint16, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(addr), "$"), "0x")
+
+	value, err := strconv.ParseUint(trimmed, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address %q", addr)
+	}
+
+	return uint16(value), nil
+}
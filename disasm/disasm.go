@@ -0,0 +1,169 @@
+// Package disasm turns machine code back into assembly text
+// Decoding uses the same instruction tables as the CPU, so the disassembly
+// always agrees with what halken actually executes
+// Immediates are filled in, relative jumps are resolved to their target, and
+// jump targets in ROM are annotated with the bank they land in
+package disasm
+
+import (
+	"fmt"
+	"strings"
+
+	"../cpu"
+)
+
+// Memory is anything instructions can be decoded from
+type Memory interface {
+	// Read returns the byte at addr, ok is false if addr isn't readable
+	Read(addr uint16) (data byte, ok bool)
+	// Bank returns the ROM bank mapped at addr
+	Bank(addr uint16) int
+}
+
+// Line is a single decoded instruction
+type Line struct {
+	Bank  int
+	Addr  uint16
+	Bytes []byte
+	// Assembly with operands filled in
+	Text string
+}
+
+// String formats the line as bank:address, raw bytes, then assembly
+func (line Line) String() string {
+	hex := make([]string, len(line.Bytes))
+	for i, data := range line.Bytes {
+		hex[i] = fmt.Sprintf("%02X", data)
+	}
+
+	return fmt.Sprintf("%02X:%04X  %-8s  %s", line.Bank, line.Addr, strings.Join(hex, " "), line.Text)
+}
+
+// Range decodes instructions from addr from up to and including addr to
+// Decoding stops early at the end of readable memory
+func Range(mem Memory, from, to uint16) []Line {
+	var lines []Line
+
+	for addr := uint32(from); addr <= uint32(to); {
+		line, ok := Decode(mem, uint16(addr))
+		if !ok {
+			break
+		}
+
+		lines = append(lines, line)
+		addr += uint32(len(line.Bytes))
+	}
+
+	return lines
+}
+
+// Count decodes count instructions starting at addr from
+// Decoding stops early at the end of readable memory
+func Count(mem Memory, from uint16, count int) []Line {
+	var lines []Line
+
+	for addr := uint32(from); len(lines) < count && addr <= 0xFFFF; {
+		line, ok := Decode(mem, uint16(addr))
+		if !ok {
+			break
+		}
+
+		lines = append(lines, line)
+		addr += uint32(len(line.Bytes))
+	}
+
+	return lines
+}
+
+// Decode decodes the instruction at addr
+// Illegal opcodes, and instructions cut off by the end of readable memory,
+// are decoded as a single DB byte
+// ok is false if addr itself isn't readable
+func Decode(mem Memory, addr uint16) (line Line, ok bool) {
+	opcode, ok := mem.Read(addr)
+	if !ok {
+		return Line{}, false
+	}
+
+	line = Line{
+		Bank:  mem.Bank(addr),
+		Addr:  addr,
+		Bytes: []byte{opcode},
+		Text:  fmt.Sprintf("DB $%02X", opcode),
+	}
+
+	instr := cpu.Instructions[opcode]
+	if instr.NumOperands == 0 {
+		line.Text += " ; illegal opcode"
+		return line, true
+	}
+
+	for i := uint16(1); i < instr.NumOperands; i++ {
+		data, ok := mem.Read(addr + i)
+		if !ok {
+			return line, true
+		}
+		line.Bytes = append(line.Bytes, data)
+	}
+
+	if opcode == 0xCB {
+		line.Text = cpu.InstructionsCB[line.Bytes[1]].Mnemonic
+		return line, true
+	}
+
+	line.Text = fillOperands(mem, instr.Mnemonic, addr, line.Bytes)
+	return line, true
+}
+
+// fillOperands replaces the operand placeholders in mnemonic with the values
+// in the instruction's bytes
+// See cpu/instructions.go for what each placeholder means
+func fillOperands(mem Memory, mnemonic string, addr uint16, data []byte) string {
+	switch {
+	case strings.Contains(mnemonic, "i16"):
+		return strings.Replace(mnemonic, "i16", fmt.Sprintf("$%04X", join(data)), 1)
+
+	case strings.Contains(mnemonic, "a16"):
+		target := join(data)
+		text := strings.Replace(mnemonic, "a16", fmt.Sprintf("$%04X", target), 1)
+		if strings.HasPrefix(mnemonic, "JP") || strings.HasPrefix(mnemonic, "CALL") {
+			text += annotateTarget(mem, target)
+		}
+		return text
+
+	case strings.Contains(mnemonic, "i8"):
+		return strings.Replace(mnemonic, "i8", fmt.Sprintf("$%02X", data[1]), 1)
+
+	case strings.Contains(mnemonic, "$FF00+a8"):
+		return strings.Replace(mnemonic, "$FF00+a8", fmt.Sprintf("$FF%02X", data[1]), 1)
+
+	case strings.HasPrefix(mnemonic, "JR"):
+		// Relative to the address after the JR
+		target := addr + 2 + uint16(int8(data[1]))
+		text := strings.Replace(mnemonic, "s8", fmt.Sprintf("$%04X", target), 1)
+		return text + annotateTarget(mem, target)
+
+	case strings.Contains(mnemonic, "+s8"):
+		return strings.Replace(mnemonic, "+s8", fmt.Sprintf("%+d", int8(data[1])), 1)
+
+	case strings.Contains(mnemonic, "s8"):
+		return strings.Replace(mnemonic, "s8", fmt.Sprintf("%d", int8(data[1])), 1)
+	}
+
+	return mnemonic
+}
+
+// annotateTarget returns a comment with the bank a jump lands in
+// Only targets in ROM have a bank worth mentioning
+func annotateTarget(mem Memory, target uint16) string {
+	if target >= romEnd {
+		return ""
+	}
+
+	return fmt.Sprintf(" ; %02X:%04X", mem.Bank(target), target)
+}
+
+// join returns the little endian 16-bit operand following the opcode
+func join(data []byte) uint16 {
+	return uint16(data[2])<<8 | uint16(data[1])
+}
@@ -0,0 +1,90 @@
+package disasm
+
+import (
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		addr  uint16
+		bytes []byte
+		// Expected bytes decoded, if fewer than given
+		decoded  int
+		expected string
+	}{
+		{0x0150, []byte{0x3E, 0x12}, 0, "LD A,$12"},
+		{0x0150, []byte{0x01, 0x34, 0x12}, 0, "LD BC,$1234"},
+		{0x0150, []byte{0xE0, 0x44}, 0, "LD ($FF44),A"},
+		{0x0150, []byte{0xEA, 0x00, 0xC0}, 0, "LD ($C000),A"},
+		{0x0150, []byte{0xC3, 0x67, 0x45}, 0, "JP $4567 ; 02:4567"},
+		{0x4100, []byte{0xCD, 0x50, 0x01}, 0, "CALL $0150 ; 00:0150"},
+		{0x0200, []byte{0x18, 0xFE}, 0, "JR $0200 ; 00:0200"},
+		{0x4000, []byte{0x20, 0x05}, 0, "JR NZ,$4007 ; 02:4007"},
+		{0x4000, []byte{0x38, 0xF0}, 0, "JR C,$3FF2 ; 00:3FF2"},
+		{0x0150, []byte{0xF8, 0xFE}, 0, "LD HL,SP-2"},
+		{0x0150, []byte{0xE8, 0x05}, 0, "ADD SP,5"},
+		{0x0150, []byte{0xCB, 0x37}, 0, "SWAP A"},
+		{0x0150, []byte{0xCB, 0x7C}, 0, "BIT 7,H"},
+		{0x0150, []byte{0xCF}, 0, "RST 08H"},
+		{0x0150, []byte{0xFF}, 0, "RST 38H"},
+		{0x0150, []byte{0xD3}, 0, "DB $D3 ; illegal opcode"},
+		{0x7FFF, []byte{0xC3}, 0, "DB $C3"},
+		{0x7FFE, []byte{0xCB, 0x37, 0x00}, 2, "SWAP A"},
+	}
+
+	for _, test := range tests {
+		// 3 banks with bank 2 selected, so banks show up in jump targets
+		data := make([]byte, 3*BankSize)
+		offset := int(test.addr)
+		if test.addr >= BankSize {
+			offset += BankSize
+		}
+		copy(data[offset:], test.bytes)
+
+		rom, err := NewROM(data, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line, ok := Decode(rom, test.addr)
+		if !ok {
+			t.Errorf("% X: address $%04X isn't readable", test.bytes, test.addr)
+			continue
+		}
+
+		decoded := test.decoded
+		if decoded == 0 {
+			decoded = len(test.bytes)
+		}
+		if line.Text != test.expected || len(line.Bytes) != decoded {
+			t.Errorf("% X at $%04X decoded as %q from %d bytes, expected %q from %d", test.bytes, test.addr, line.Text, len(line.Bytes), test.expected, decoded)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	rom, err := NewROM([]byte{
+		0x00,       // 0000: NOP
+		0x3E, 0x01, // 0001: LD A,$01
+		0xC3, 0x00, 0x00, // 0003: JP $0000
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Decoding stops at the end of the ROM, even though the range goes on
+	lines := Range(rom, 0x0000, 0x0010)
+	expected := []uint16{0x0000, 0x0001, 0x0003}
+	if len(lines) != len(expected) {
+		t.Fatalf("decoded %d lines, expected %d", len(lines), len(expected))
+	}
+	for i, addr := range expected {
+		if lines[i].Addr != addr {
+			t.Errorf("line %d is at $%04X, expected $%04X", i, lines[i].Addr, addr)
+		}
+	}
+
+	if got := lines[2].String(); got != "00:0003  C3 00 00  JP $0000 ; 00:0000" {
+		t.Errorf("line formatted as %q", got)
+	}
+}
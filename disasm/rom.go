@@ -0,0 +1,56 @@
+package disasm
+
+import (
+	"fmt"
+)
+
+// BankSize is the size of a ROM bank
+// Bank 0 is always mapped at 0x0000-0x3FFF, and the memory bank controller
+// picks which bank is mapped at 0x4000-0x7FFF
+const BankSize = 0x4000
+
+// End of ROM in the CPU's address space
+const romEnd = 2 * BankSize
+
+// ROM is a cartridge ROM as the CPU sees it, with one bank selected
+type ROM struct {
+	data []byte
+	bank int
+}
+
+// NewROM maps bank of the cartridge ROM data at 0x4000-0x7FFF
+func NewROM(data []byte, bank int) (*ROM, error) {
+	banks := (len(data) + BankSize - 1) / BankSize
+	if bank < 0 || bank >= banks {
+		return nil, fmt.Errorf("disasm: ROM has %d banks, can't select bank %d", banks, bank)
+	}
+
+	return &ROM{data: data, bank: bank}, nil
+}
+
+// Read returns the byte at addr, or false if addr is outside of ROM
+func (rom *ROM) Read(addr uint16) (byte, bool) {
+	if addr >= romEnd {
+		return 0, false
+	}
+
+	offset := int(addr)
+	if addr >= BankSize {
+		offset = rom.bank*BankSize + int(addr-BankSize)
+	}
+
+	if offset >= len(rom.data) {
+		return 0, false
+	}
+
+	return rom.data[offset], true
+}
+
+// Bank returns 0 below 0x4000, otherwise the selected bank
+func (rom *ROM) Bank(addr uint16) int {
+	if addr < BankSize {
+		return 0
+	}
+
+	return rom.bank
+}
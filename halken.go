@@ -3,16 +3,22 @@ package main
 // Main creates all Game Boy components and handles main loop
 
 import (
-	"encoding/binary"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"time"
 
+	"./apu"
 	"./cpu"
+	"./interrupts"
 	"./io"
 	"./lcd"
 	"./mmu"
+	"./serial"
 	"./timer"
 	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/audio"
 )
 
 // GB can execute 4194304 (4.19MHz) cycles per second
@@ -20,66 +26,298 @@ import (
 // maximum number of cycles allowed to be executed per frame
 const maxCycles = 69905
 
-// Create Game Boy components
+// Game Boy components, created by newSystem
 // The US patent provides good visual breakdown of components in figure 4
 // Patent reference: https://patents.google.com/patent/US5184830A/en
 
 // GbCPU represents GB's CPU - patent fig. 4, #24
-var GbCPU = new(cpu.GBCPU)
+var GbCPU *cpu.GBCPU
 
 // GbTimer represents GB's timer - patent fig. 4, #24d
-var GbTimer = new(timer.GBTimer)
+var GbTimer *timer.GBTimer
 
 // GbMMU represents all available GB memory - patent fig. 4, #s 16, 28, 30, 36, 42
-var GbMMU = new(mmu.GBMMU)
+var GbMMU *mmu.GBMMU
 
 // GbLCD represents GB's LCD components and physical screen
 // patent fig. 4, #s 14, 38, 40, 44, 48
-var GbLCD = new(lcd.GBLCD)
+var GbLCD *lcd.GBLCD
 
 // GbIO represents GB's "controller key matrix" and I/O port
 // patent fig. 4, #s 18, 27
-var GbIO = new(io.GBIO)
+var GbIO *io.GBIO
+
+// GbSerial represents GB's serial port, used by the link cable
+// patent fig. 4, #27
+var GbSerial *serial.GBSerial
+
+// GbAPU represents GB's sound circuit - patent fig. 4, #24f
+var GbAPU *apu.GBAPU
+
+// GbInterrupts represents GB's interrupt controller, part of the CPU
+// patent fig. 4, #24
+var GbInterrupts *interrupts.GBInterrupts
+
+// Command line flags
+var (
+	bootFlag    = flag.Bool("boot", false, "run the built-in DMG boot ROM before the game")
+	bootROMFlag = flag.String("bootrom", "", "run the DMG, MGB or CGB boot ROM at `path` before the game")
+
+	headlessFlag = flag.Bool("headless", false, "run a test ROM without a window, printing its output and exiting with its result code")
+	timeoutFlag  = flag.Duration("timeout", 2*time.Minute, "emulated time a headless test may run for before failing")
+
+	sampleRateFlag = flag.Int("samplerate", 44100, "audio output sample rate in Hz")
+	paletteFlag    = flag.String("palette", lcd.DefaultPalette, "colors the 4 shades are drawn with: green, gray, or 4 hex `colors` like FFFFFF,AAAAAA,555555,000000")
+
+	traceFlag       = flag.String("trace", "", "write a Gameboy Doctor trace of executed instructions to `path`, - for stdout")
+	traceRingFlag   = flag.Int("tracering", 0, "only keep the last `n` trace lines, written on exit or when the CPU locks up")
+	traceRangeFlag  = flag.String("tracerange", "", "only trace instructions with PC in the hex `range` START-END")
+	traceBankFlag   = flag.Int("tracebank", cpu.AnyBank, "only trace instructions in ROM `bank`, -1 for any")
+	tracePausedFlag = flag.Bool("tracepaused", false, "start with tracing paused, press T to toggle it")
+	doctorFlag      = flag.Bool("doctor", false, "make LY always read $90, like Gameboy Doctor's reference logs")
+)
 
 func main() {
-	cartPath := os.Args[1]
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: halken [flags] /path/to/rom\n")
+		fmt.Fprintf(os.Stderr, "       halken disasm /path/to/rom [flags]\n")
+		fmt.Fprintf(os.Stderr, "       halken cputest [flags] /path/to/vectors\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Commands that don't run the emulator
+	switch flag.Arg(0) {
+	case "disasm":
+		os.Exit(runDisasm(flag.Args()[1:]))
+	case "cputest":
+		os.Exit(runCPUTest(flag.Args()[1:]))
+	}
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cartPath := flag.Arg(0)
+
+	err := startSystem(cartPath)
+	if err != nil {
+		fmt.Printf("main: %s\n", err)
+		os.Exit(1)
+	}
+
+	err = startTrace()
+	if err != nil {
+		fmt.Printf("main: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Test ROMs can be run without graphics
+	if *headlessFlag {
+		code := runHeadless(*timeoutFlag)
+		printRumble()
+
+		err = stopTrace()
+		if err != nil {
+			fmt.Printf("main: %s\n", err)
+		}
+		os.Exit(code)
+	}
+
+	// Play samples generated by the APU
+	err = startAudio()
+	if err != nil {
+		fmt.Printf("main: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Kick off main emulation loop & create graphics context
+	// Blocks until the window is closed
+	ebiten.Run(run, 160, 144, 4, "Halken - "+GbMMU.Header.Title)
+
+	err = stopTrace()
+	if err != nil {
+		fmt.Printf("main: %s\n", err)
+	}
+
+	// Write battery backed cartridge RAM to disk so progress isn't lost
+	err = GbMMU.SaveCart()
+	if err != nil {
+		fmt.Printf("main: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// newSystem creates every component and injects them into the packages that
+// need to use them, replacing any components created before
+func newSystem() {
+	GbCPU = new(cpu.GBCPU)
+	GbTimer = new(timer.GBTimer)
+	GbMMU = new(mmu.GBMMU)
+	GbLCD = new(lcd.GBLCD)
+	GbIO = new(io.GBIO)
+	GbSerial = new(serial.GBSerial)
+	GbAPU = new(apu.GBAPU)
+	GbInterrupts = new(interrupts.GBInterrupts)
 
-	// Inject components into packages that need to use them
 	cpu.GbMMU = GbMMU
+	cpu.GbBus = GbMMU
 	lcd.GbMMU = GbMMU
 	timer.GbMMU = GbMMU
 
 	mmu.GbIO = GbIO
 	lcd.GbIO = GbIO
 
+	mmu.GbSerial = GbSerial
+	mmu.GbAPU = GbAPU
+
+	cpu.GbInterrupts = GbInterrupts
+	mmu.GbInterrupts = GbInterrupts
+	lcd.GbInterrupts = GbInterrupts
+	timer.GbInterrupts = GbInterrupts
+	serial.GbInterrupts = GbInterrupts
+	io.GbInterrupts = GbInterrupts
+
 	lcd.GbCPU = GbCPU
 
+	cpu.Tick = tick
+	GbCPU.OnFault = printFault
+	mmu.ResetDivider = GbTimer.ResetDivider
+	timer.ClockSerial = GbSerial.Clock
+
+	GbMMU.Rumble = rumble
+	rumbleOn = false
+	rumbleCount = 0
+
 	lcd.GbTimer = GbTimer
+}
+
+// startSystem creates the components, loads the cartridge at cartPath and
+// initializes everything so the game is ready to run
+// Options like the palette and boot ROM are taken from the command line flags
+func startSystem(cartPath string) error {
+	newSystem()
 
 	// Call initialization functions for components
 	// Necessary to set default values
+	// The cartridge is loaded before the CPU is initialized, since the
+	// header decides which model's register values the CPU starts with
 	GbMMU.InitMMU()
-	GbCPU.InitCPU()
+
+	// Test ROMs write their results to cartridge RAM, so saving it would
+	// leave files next to the ROM and start the next run from the last one's
+	// results
+	GbMMU.DisableSaves = *headlessFlag
+
+	err := GbMMU.LoadCart(cartPath)
+	if err != nil {
+		return err
+	}
+
+	// Problems with the header aren't fatal, but are worth knowing about
+	// when a game doesn't run correctly
+	for _, problem := range GbMMU.CartProblems {
+		fmt.Printf("main: warning: %s\n", problem)
+	}
+
+	GbCPU.InitCPU(GbMMU.Header.Model())
 	GbIO.InitIO()
 	GbLCD.InitLCD()
+	GbLCD.Palette, err = lcd.ParsePalette(*paletteFlag)
+	if err != nil {
+		return err
+	}
+	GbSerial.InitSerial()
+	GbAPU.InitAPU(GbMMU.Header.Model(), *sampleRateFlag)
+	GbInterrupts.InitInterrupts()
 
-	err := GbMMU.LoadCart(cartPath)
+	// Optionally start at 0x0000 with a boot ROM mapped, rather than at
+	// 0x0100 with the values the boot ROM would have left behind
+	err = loadBootROM()
 	if err != nil {
-		fmt.Printf("main: %s\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	// Kick off main emulation loop & create graphics context
-	ebiten.Run(run, 160, 144, 4, "Halken")
+	GbMMU.StubLY = *doctorFlag
+
+	return nil
+}
+
+// loadBootROM maps the boot ROM selected by command line flags, if any
+func loadBootROM() error {
+	var bootROM []byte
+
+	if *bootROMFlag != "" {
+		data, err := ioutil.ReadFile(*bootROMFlag)
+		if err != nil {
+			return err
+		}
+		bootROM = data
+	} else if *bootFlag {
+		bootROM = mmu.BootstrapROM[:]
+	} else {
+		return nil
+	}
+
+	err := GbMMU.LoadBootROM(bootROM)
+	if err != nil {
+		return err
+	}
+
+	GbCPU.Regs.InitPowerOn()
+
+	// The boot ROM turns the APU on itself, and nothing has requested an
+	// interrupt yet
+	GbAPU.PowerOff()
+	GbInterrupts.WriteIF(0x00)
+
+	return nil
+}
+
+// printFault reports why the CPU locked up, along with the instructions that
+// led up to it
+// The game stays frozen rather than exiting, like it would on hardware
+func printFault(fault *cpu.Fault) {
+	fmt.Printf("main: %s\n", fault)
+	fmt.Printf("main: last %d instructions:\n", len(fault.History))
+	for _, executed := range fault.History {
+		fmt.Printf("  %s\n", executed)
+	}
+
+	// Make sure the trace leading up to the fault isn't lost, in case the
+	// emulator is killed rather than closed
+	if GbCPU.Tracer != nil {
+		err := GbCPU.Tracer.Flush()
+		if err != nil {
+			fmt.Printf("main: trace: %s\n", err)
+		}
+	}
+}
+
+// startAudio creates an audio player that reads samples from the APU
+// The player pulls samples on its own goroutine for as long as the game runs
+func startAudio() error {
+	context, err := audio.NewContext(*sampleRateFlag)
+	if err != nil {
+		return err
+	}
+
+	player, err := audio.NewPlayer(context, GbAPU)
+	if err != nil {
+		return err
+	}
+
+	return player.Play()
 }
 
 // run is the primary emulation loop, called 60 times per second by ebiten
 func run(screen *ebiten.Image) error {
 	// Read inputs prior to updating state
 	GbIO.ReadInput()
+	toggleTrace()
 
 	// Execute next instruction and update graphics state
-	update(screen)
+	update()
 
 	// Update window, which is just an image
 	GbLCD.DrawFrame()
@@ -93,168 +331,58 @@ func run(screen *ebiten.Image) error {
 }
 
 // update:
-// 1. Executes next operation
-// 2. Updates total cycles
-// 3. Updates timers
-// 4. Updates window's state
-// 5. Performs interrupts
-// TODO This might be too much of a god function, maybe break down
-func update(screen *ebiten.Image) {
+// 1. Performs interrupts
+// 2. Executes next operation, which ticks the rest of the system as it goes
+// 3. Updates total cycles
+func update() {
 	// Counter for total number of cycles executed for this frame
 	updateCycles := 0
 
-	for updateCycles < maxCycles {
-		// First, we need to check if the CPU is halted
-		// This is indicated by a boolean which gets set if HALT is called
-		if !GbCPU.Halted {
-			if GbCPU.EIReceived {
-				// If we aren't halted, but EI (enable interrupts) was called
-				// last cycle, we set the Interrupt Master Enable to 1
-				// IME globally enables/disables interrupts
-				GbCPU.IME = 1
-			}
-
-			// If Interrupt Master Flag is set, and Interrupt Enable register is
-			// nonzero, and Interrupt Flag register is nonzero,
-			// then we execute an interrupt
-			if GbCPU.IME != 0 && GbMMU.Memory[0xFFFF] != 0 && GbMMU.Memory[0xFF0F] != 0 {
-				// Get the bit of the interrupt to execute
-				interrupt := GbMMU.Memory[0xFFFF] & GbMMU.Memory[0xFF0F]
-
-				if interrupt&1 != 0 {
-					// Run VBlank interrupt handler
-					GbCPU.RSTI(0x40)
-
-					// Clear VBlank interrupt request bit
-					GbMMU.Memory[0xFF0F] &^= (1 << 0)
-					updateCycles += 16
-				} else if interrupt&4 != 0 {
-					// Run timer interrupt handler
-					GbCPU.RSTI(0x50)
-
-					// Clear timer interrupt request bit
-					GbMMU.Memory[0xFF0F] &^= (1 << 2)
-					updateCycles += 16
-				}
-			}
-
-			// Set Jumped to false in case the last instruction was a jump
-			GbCPU.Jumped = false
-
-			// Get value of PC as an integer, find next opcode to execute
-			// by getting byte at that index in memory
-			opcode := GbCPU.Regs.PC[:]
-			opcodeInt := binary.LittleEndian.Uint16(opcode)
-			operation := GbMMU.Memory[opcodeInt]
-
-			// fmt.Printf("%02X:%02X\t%v\n", opcode[1], opcode[0], GbCPU.Instrs[operation].Mnemonic)
-
-			// Execute the next instruction
-			// Delay is set to the return value of the instruction's executor
-			// This is important because certain instructions take a different
-			// number of cycles depending on if they "completed" or not
-			// For example, RET Z takes 8 cycles by default, but takes 20 cycles
-			// if the zero flag was set and the RET happened
-			// If an instruction does not have conditional # of cycles, the
-			// executor just returns 0
-			delay := GbCPU.Instrs[operation].Executor()
-
-			// Update total cycles executed for this frame
-			// If the instruction had conditional number of cycles, delay will
-			// be nonzero and added to the base # of cycles for the instruction
-			updateCycles += int(GbCPU.Instrs[operation].TCycles) + delay
-
-			// Update graphics
-			// Note we do NOT pass updateCycles here since that represents the
-			// total number of cycles in this frame
-			// We only want to pass the number of cycles taken by the previous
-			// instruction
-			GbLCD.UpdateLCD(int(GbCPU.Instrs[operation].TCycles)+delay, screen)
-
-			// Increment the timer
-			// See timer/timer.go for details
-			GbTimer.Increment(updateCycles)
-
-			// If the last instruction changed the value of the program counter
-			// then a jump occurred
-			// In this case we don't want to increment the PC
-			// Otherwise, increment the PC by the number of operands (bytes) of
-			// the last instruction
-			if GbCPU.Jumped {
-				continue
-			} else {
-				nextInstr := binary.LittleEndian.Uint16(GbCPU.Regs.PC) + GbCPU.Instrs[operation].NumOperands
-				// TODO Maybe don't need to do this anymore?
-				nextInstrAdddr := make([]byte, 2)
-				binary.LittleEndian.PutUint16(nextInstrAdddr, nextInstr)
-				GbCPU.Regs.PC = nextInstrAdddr
-			}
-
-			GbTimer.Increment(updateCycles)
-		} else {
-			// CPU is halted
-			instrTotal := 0
-
-			// Get the current value of the Interrupt Flag register
-			currentIF := GbMMU.ReadData(0xFF0F)
-
-			// If IF's current value is different than the value it was prior
-			// to HALT being called, unhalt
-			if currentIF != GbCPU.IFPreHalt {
-				GbCPU.Halted = false
-			}
-
-			// Check for interrupts, as above
-			if GbCPU.IME != 0 && GbMMU.Memory[0xFFFF] != 0 && GbMMU.Memory[0xFF0F] != 0 {
-				interrupt := GbMMU.Memory[0xFFFF] & GbMMU.Memory[0xFF0F]
-
-				if interrupt&1 != 0 {
-					// Run VBlank interrupt handler
-					GbCPU.RSTI(0x40)
-
-					// Clear VBlank interrupt request bit
-					GbMMU.Memory[0xFF0F] &^= (1 << 0)
-					updateCycles += 16
-					instrTotal += 16
-				} else if interrupt&2 != 0 {
-					// Run LCD STAT interrupt handler
-					GbCPU.RSTI(0x48)
-
-					// Clear LCD STAT interrupt request bit
-					GbMMU.Memory[0xFF0F] &^= (1 << 1)
-					instrTotal += 16
-					updateCycles += 16
-				} else if interrupt&4 != 0 {
-					// Run timer overflow interrupt handler
-					GbCPU.RSTI(0x50)
-
-					// Clear timer overflow interrupt request bit
-					GbMMU.Memory[0xFF0F] &^= (1 << 2)
-					instrTotal += 16
-					updateCycles += 16
-				} else if interrupt&8 != 0 {
-					// Run serial link interrupt handler
-					GbCPU.RSTI(0x58)
-
-					// Clear serial link interrupt request bit
-					GbMMU.Memory[0xFF0F] &^= (1 << 3)
-					instrTotal += 16
-					updateCycles += 16
-				} else if interrupt&16 != 0 {
-					// Run joypad interrupt handler
-					GbCPU.RSTI(0x60)
-
-					// Clear joypad interrupt request bit
-					GbMMU.Memory[0xFF0F] &^= (1 << 4)
-					instrTotal += 16
-					updateCycles += 16
-				}
-			}
-
-			// Halted CPU still takes 1 cycle by default
-			updateCycles++
-			GbTimer.Increment(updateCycles)
-			GbLCD.UpdateLCD(1+instrTotal, screen)
-		}
+	// The CPU executes twice as many cycles per frame in double speed
+	frameCycles := maxCycles
+	if GbCPU.DoubleSpeed {
+		frameCycles *= 2
+	}
+
+	for updateCycles < frameCycles {
+		// Service a pending interrupt or execute the next instruction
+		// If the CPU is halted, this waits for an interrupt instead
+		// Cycles is the number of cycles that took
+		// This is important because certain instructions take a different
+		// number of cycles depending on if they "completed" or not
+		// For example, RET Z takes 8 cycles by default, but takes 20 cycles
+		// if the zero flag was set and the RET happened
+		// The LCD, timer, etc. are ticked by the CPU as the instruction
+		// runs, see tick
+		updateCycles += GbCPU.Step()
+	}
+}
+
+// tick runs every component other than the CPU for the number of cycles
+// The CPU calls this through cpu.Tick as it accesses memory, so the LCD,
+// timer, etc. see each access on the cycle it happens
+func tick(cycles int) {
+	// In CGB double speed mode, the timer and serial port run at the CPU's
+	// speed, but everything else stays at normal speed
+	normalCycles := cycles
+	if GbCPU.DoubleSpeed {
+		normalCycles /= 2
 	}
+
+	// Update graphics
+	GbLCD.UpdateLCD(normalCycles)
+
+	// Update cartridge hardware like the MBC3 real-time clock
+	// This also periodically writes battery backed RAM to disk
+	err := GbMMU.UpdateCart(normalCycles)
+	if err != nil {
+		fmt.Printf("update: %s\n", err)
+	}
+
+	// Increment the timer
+	// This also shifts serial transfer bits, see timer/timer.go for details
+	GbTimer.Increment(cycles)
+
+	// Generate sound
+	GbAPU.Update(normalCycles)
 }
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"./cartridge"
+)
+
+// testROMs are the test ROMs under tests/, run through headless mode
+// ROMs with a skip reason are known to fail, see the README's known bugs
+var testROMs = []struct {
+	path string
+	skip string
+}{
+	{path: "tests/cpu_instrs/cpu_instrs.gb"},
+	{path: "tests/cpu_instrs/individual/01-special.gb"},
+	{path: "tests/cpu_instrs/individual/02-interrupts.gb"},
+	{path: "tests/cpu_instrs/individual/03-op sp,hl.gb"},
+	{path: "tests/cpu_instrs/individual/04-op r,imm.gb"},
+	{path: "tests/cpu_instrs/individual/05-op rp.gb"},
+	{path: "tests/cpu_instrs/individual/06-ld r,r.gb"},
+	{path: "tests/cpu_instrs/individual/07-jr,jp,call,ret,rst.gb"},
+	{path: "tests/cpu_instrs/individual/08-misc instrs.gb"},
+	{path: "tests/cpu_instrs/individual/09-op r,r.gb"},
+	{path: "tests/cpu_instrs/individual/10-bit ops.gb"},
+	{path: "tests/cpu_instrs/individual/11-op a,(hl).gb"},
+	{path: "tests/instr_timing.gb"},
+	{path: "tests/mem_timing.gb"},
+	{path: "tests/halt_bug.gb"},
+	{path: "tests/interrupt_time.gb"},
+	{path: "tests/oam_bug.gb", skip: "OAM corruption isn't emulated"},
+	{path: "tests/dmg_sound.gb"},
+	{path: "tests/cgb_sound.gb", skip: "sub-tests 08 and 11 fail, CGB specific APU behaviour isn't emulated"},
+}
+
+// Emulated time a test ROM may run for before it counts as failed
+const testROMTimeout = 2 * time.Minute
+
+func TestROMs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("test ROMs take a few seconds to run")
+	}
+
+	*headlessFlag = true
+	for _, rom := range testROMs {
+		t.Run(rom.path, func(t *testing.T) {
+			if rom.skip != "" {
+				t.Skip(rom.skip)
+			}
+
+			err := startSystem(rom.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			code := runHeadless(testROMTimeout)
+			if code != 0 {
+				t.Errorf("exited with code %d", code)
+			}
+		})
+	}
+}
+
+// rumbleROM is an MBC5 rumble cartridge that turns the motor on and off 3
+// times, then loops forever
+func rumbleROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], []byte{
+		0x00,             // NOP
+		0xC3, 0x50, 0x01, // JP $0150
+	})
+	rom[0x147] = 0x1C // MBC5+RUMBLE
+
+	code := []byte{}
+	for i := 0; i < 3; i++ {
+		code = append(code,
+			0x3E, 0x08, // LD A,$08
+			0xEA, 0x00, 0x40, // LD ($4000),A
+			0x3E, 0x00, // LD A,$00
+			0xEA, 0x00, 0x40, // LD ($4000),A
+		)
+	}
+	code = append(code, 0x18, 0xFE) // JR -2
+	copy(rom[0x150:], code)
+
+	return rom
+}
+
+func TestRumble(t *testing.T) {
+	cleanup := startTestROM(t, "rumble.gb", rumbleROM())
+	defer cleanup()
+
+	runHeadless(100 * time.Millisecond)
+	if rumbleCount != 3 {
+		t.Errorf("motor turned on %d times, expected 3", rumbleCount)
+	}
+	if rumbleOn {
+		t.Errorf("motor is still on")
+	}
+}
+
+// startTestROM writes rom to a temporary directory and starts the system
+// with it in headless mode
+// Returns a function that removes the directory
+func startTestROM(t *testing.T, name string, rom []byte) func() {
+	dir, err := ioutil.TempDir("", "halken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, name)
+	err = ioutil.WriteFile(path, rom, 0644)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	*headlessFlag = true
+	err = startSystem(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return func() {
+		os.RemoveAll(dir)
+	}
+}
+
+// captureOutput returns what f prints to stdout
+func captureOutput(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = stdout
+	w.Close()
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(out)
+}
+
+// screenROM is a cartridge with the given global checksum which writes
+// "Passed" to the background map, then loops forever
+func screenROM(checksum uint16) []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], []byte{
+		0x00,             // NOP
+		0xC3, 0x50, 0x01, // JP $0150
+	})
+	binary.BigEndian.PutUint16(rom[0x14E:], checksum)
+
+	code := []byte{0x21, 0x00, 0x98} // LD HL,$9800
+	for _, char := range []byte("Passed") {
+		code = append(code,
+			0x3E, char, // LD A,char
+			0x22, // LD (HL+),A
+		)
+	}
+	code = append(code, 0x18, 0xFE) // JR -2
+	copy(rom[0x150:], code)
+
+	return rom
+}
+
+// The screen is only read as text for the ROMs known to need it, others
+// might show anything on screen
+func TestScreenResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		checksum uint16
+		code     int
+	}{
+		{"halt_bug", 0x8625, 0},
+		{"other ROM", 0x1234, exitTimeout},
+	}
+
+	for _, test := range tests {
+		cleanup := startTestROM(t, "screen.gb", screenROM(test.checksum))
+
+		var code int
+		out := captureOutput(t, func() {
+			code = runHeadless(time.Second)
+		})
+		cleanup()
+
+		if code != test.code {
+			t.Errorf("%s: exited with code %d, expected %d", test.name, code, test.code)
+		}
+		if test.code == 0 && !strings.Contains(out, "Passed") {
+			t.Errorf("%s: printed %q, expected the screen's text", test.name, out)
+		}
+	}
+}
+
+// serialThenRAMROM is a cartridge with RAM which sends "X" over the serial
+// port, then reports "ok" and a pass through cartridge RAM
+func serialThenRAMROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], []byte{
+		0x00,             // NOP
+		0xC3, 0x50, 0x01, // JP $0150
+	})
+	rom[0x147] = 0x03 // MBC1+RAM+BATTERY
+	rom[0x149] = 0x02 // 8KB RAM
+
+	copy(rom[0x150:], []byte{
+		0x3E, 0x0A, // 0150: LD A,$0A
+		0xEA, 0x00, 0x00, // 0152: LD ($0000),A
+		0x3E, 'X', // 0155: LD A,'X'
+		0xE0, 0x01, // 0157: LDH ($01),A
+		0x3E, 0x81, // 0159: LD A,$81
+		0xE0, 0x02, // 015B: LDH ($02),A
+		0x01, 0x00, 0x20, // 015D: LD BC,$2000
+		0x0B,       // 0160: DEC BC
+		0x78,       // 0161: LD A,B
+		0xB1,       // 0162: OR C
+		0x20, 0xFB, // 0163: JR NZ,$0160
+		0x21, 0x04, 0xA0, // 0165: LD HL,$A004
+		0x3E, 'o', // 0168: LD A,'o'
+		0x22,      // 016A: LD (HL+),A
+		0x3E, 'k', // 016B: LD A,'k'
+		0x22,             // 016D: LD (HL+),A
+		0xAF,             // 016E: XOR A
+		0x77,             // 016F: LD (HL),A
+		0xEA, 0x00, 0xA0, // 0170: LD ($A000),A
+		0x3E, 0xDE, // 0173: LD A,$DE
+		0xEA, 0x01, 0xA0, // 0175: LD ($A001),A
+		0x3E, 0xB0, // 0178: LD A,$B0
+		0xEA, 0x02, 0xA0, // 017A: LD ($A002),A
+		0x3E, 0x61, // 017D: LD A,$61
+		0xEA, 0x03, 0xA0, // 017F: LD ($A003),A
+		0x18, 0xFE, // 0182: JR -2
+	})
+
+	return rom
+}
+
+// Text already printed from the serial port doesn't count towards the text
+// in cartridge RAM
+func TestSerialThenRAMText(t *testing.T) {
+	cleanup := startTestROM(t, "text.gb", serialThenRAMROM())
+	defer cleanup()
+
+	var code int
+	out := captureOutput(t, func() {
+		code = runHeadless(time.Second)
+	})
+
+	if code != 0 {
+		t.Errorf("exited with code %d, expected 0", code)
+	}
+	if out != "Xok\n" {
+		t.Errorf("printed %q, expected \"Xok\\n\"", out)
+	}
+}
+
+// benchLoop loops over a mix of loads, ALU ops, a CB prefixed op and jumps,
+// like the inner loops of most games
+var benchLoop = []byte{
+	0xF3,             // 0150: DI
+	0x21, 0x00, 0xC0, // 0151: LD HL,$C000
+	0x06, 0x00, // 0154: LD B,$00
+	0x7E,       // 0156: LD A,(HL)
+	0x80,       // 0157: ADD A,B
+	0x22,       // 0158: LD (HL+),A
+	0xCB, 0x37, // 0159: SWAP A
+	0x04,       // 015B: INC B
+	0x20, 0xF8, // 015C: JR NZ,$0156
+	0xC3, 0x51, 0x01, // 015E: JP $0151
+}
+
+// Nearly all of benchLoop's time is spent in the 6 instructions of its inner
+// loop, which take 44 cycles
+const benchInstrsPerCycle = 6.0 / 44.0
+
+// benchROM is a 32KB cartridge with a valid header that runs benchLoop
+func benchROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], []byte{
+		0x00,             // NOP
+		0xC3, 0x50, 0x01, // JP $0150
+	})
+	copy(rom[0x104:], cartridge.NintendoLogo[:])
+	copy(rom[0x134:], "BENCH")
+	copy(rom[0x150:], benchLoop)
+
+	rom[0x14D] = cartridge.HeaderChecksum(rom)
+	binary.BigEndian.PutUint16(rom[0x14E:], cartridge.GlobalChecksum(rom))
+
+	return rom
+}
+
+// BenchmarkFrame measures how fast whole frames are emulated, with the CPU
+// ticking every other component as it would running a game
+func BenchmarkFrame(b *testing.B) {
+	dir, err := ioutil.TempDir("", "halken")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "bench.gb")
+	err = ioutil.WriteFile(path, benchROM(), 0644)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = startSystem(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		update()
+	}
+
+	seconds := b.Elapsed().Seconds()
+	b.ReportMetric(float64(b.N)/seconds, "frames/s")
+	b.ReportMetric(float64(b.N)*maxCycles*benchInstrsPerCycle/seconds, "instrs/s")
+}
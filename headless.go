@@ -0,0 +1,190 @@
+package main
+
+// Headless mode runs a ROM without creating a window
+// It's meant for blargg's test ROMs, which report their results in cartridge
+// RAM so that they can be checked without any graphics
+// See tests/README.md for a description of the protocol
+// Cartridges without RAM can't do that, but the same text is also sent over
+// the serial port, ending in "Passed" or "Failed"
+// A few tests like halt_bug and interrupt_time only print to the screen
+// For those, the background map is read as text instead
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"./serial"
+)
+
+// Blargg test ROM memory locations
+const (
+	// Overall status of the test. 0x80 while running, result code when done
+	testStatus = 0xA000
+	// $DE $B0 $61 is written here once the status and text are valid
+	testSignature = 0xA001
+	// Zero terminated text output
+	testText = 0xA004
+)
+
+// Exit code when a test doesn't report a result before the timeout
+const exitTimeout = 124
+
+// Exit code when the CPU locks up on an illegal opcode
+const exitLockedUp = 125
+
+// Status value while a test is still running
+const testRunning = 0x80
+
+// Frames per second of emulated time
+const framesPerSecond = 60
+
+// Frames to keep running after a serial result, since more details like the
+// number of failed tests are printed right after it
+const serialSettleFrames = 30
+
+// screenResultROMs are the test ROMs that only print their result on screen,
+// keyed by global checksum since they don't have a title
+// Reading the screen as text relies on these ROMs' font having its tiles in
+// ASCII order, so it isn't tried for any other ROM
+var screenResultROMs = map[uint16]string{
+	0x8625: "halt_bug",
+	0x65EA: "interrupt_time",
+}
+
+// runHeadless runs the loaded cartridge until it reports a test result, or
+// until timeout worth of emulated time has passed
+// Text output is streamed to stdout as the test writes it
+// Returns the test's result code, which is 0 if it passed
+func runHeadless(timeout time.Duration) int {
+	frames := int(timeout.Seconds() * framesPerSecond)
+
+	// Serial output and cartridge RAM text are printed as they arrive
+	// A test may switch from one to the other, so each has its own count
+	serialPrinted := 0
+	textPrinted := 0
+
+	// Result found in the serial output or on screen, and how many frames ago
+	result := -1
+	settleFrames := 0
+	_, screenResult := screenResultROMs[GbMMU.Header.GlobalChecksum]
+
+	// Capture anything sent over the serial port
+	serialOut := new(bytes.Buffer)
+	GbSerial.Peer = &serial.Capture{W: serialOut}
+
+	for frame := 0; frame < frames; frame++ {
+		update()
+
+		// A locked up CPU never reports a result, the fault has already been
+		// printed by printFault
+		if GbCPU.Locked {
+			return exitLockedUp
+		}
+
+		if !testSignatureValid() {
+			// Fall back to the serial output
+			fmt.Printf("%s", serialOut.Bytes()[serialPrinted:])
+			serialPrinted = serialOut.Len()
+
+			if result < 0 {
+				if screenResult {
+					result = textResult(screenText())
+				} else {
+					result = textResult(serialOut.Bytes())
+				}
+			} else if settleFrames++; settleFrames == serialSettleFrames {
+				if screenResult {
+					fmt.Printf("%s", screenText())
+				}
+
+				fmt.Println()
+				return result
+			}
+
+			continue
+		}
+
+		textPrinted += printTestText(textPrinted)
+
+		status := GbMMU.ReadData(testStatus)
+		if status != testRunning {
+			fmt.Println()
+			return int(status)
+		}
+	}
+
+	fmt.Printf("\nheadless: no result after %s\n", timeout)
+	return exitTimeout
+}
+
+// testSignatureValid returns true if the test ROM has written the signature
+// which indicates the status and text in cartridge RAM are valid
+func testSignatureValid() bool {
+	return GbMMU.ReadData(testSignature) == 0xDE &&
+		GbMMU.ReadData(testSignature+1) == 0xB0 &&
+		GbMMU.ReadData(testSignature+2) == 0x61
+}
+
+// textResult looks for the end of a test in its serial output, or in its
+// screen read as text
+// Returns 0 if it passed, 1 if it failed, or -1 if it's still running
+func textResult(output []byte) int {
+	if bytes.Contains(output, []byte("Passed")) {
+		return 0
+	} else if bytes.Contains(output, []byte("Failed")) {
+		return 1
+	}
+
+	return -1
+}
+
+// printTestText prints any text the test has written since the last call
+// skip is the number of characters already printed
+// Returns the number of new characters printed
+func printTestText(skip int) int {
+	count := 0
+
+	for addr := testText + skip; addr < 0xC000; addr++ {
+		char := GbMMU.ReadData(uint16(addr))
+		if char == 0 {
+			break
+		}
+
+		fmt.Printf("%c", char)
+		count++
+	}
+
+	return count
+}
+
+// screenText returns the visible part of the background map as text
+// The test ROMs' font has its tiles in ASCII order, so tile numbers can be
+// printed as characters. Trailing spaces and empty lines are left out
+func screenText() []byte {
+	// LCDC bit 3 selects the background map
+	mapAddr := uint16(0x9800)
+	if GbMMU.ReadData(0xFF40)&(1<<3) != 0 {
+		mapAddr = 0x9C00
+	}
+
+	text := new(bytes.Buffer)
+	for y := uint16(0); y < 18; y++ {
+		line := make([]byte, 20)
+		for x := uint16(0); x < 20; x++ {
+			char := GbMMU.ReadData(mapAddr + y*32 + x)
+			if char < ' ' || char > '~' {
+				char = ' '
+			}
+			line[x] = char
+		}
+
+		line = bytes.TrimRight(line, " ")
+		if len(line) > 0 {
+			text.Write(line)
+			text.WriteByte('\n')
+		}
+	}
+
+	return text.Bytes()
+}
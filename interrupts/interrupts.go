@@ -0,0 +1,91 @@
+// Package interrupts models the GB's interrupt controller
+// Components request interrupts by setting bits in IF (0xFF0F), and the
+// game chooses which ones can fire by setting bits in IE (0xFFFF)
+// The CPU services pending interrupts in priority order, see cpu.HandleInterrupts
+// Reference: http://bgb.bircd.org/pandocs.htm#interrupts
+package interrupts
+
+// Interrupt sources, in priority order
+// Each one's value is its bit in IE and IF
+const (
+	VBlank = iota
+	LCDStat
+	Timer
+	Serial
+	Joypad
+)
+
+// Only the lower 5 bits of IF are wired to anything
+const sourceMask = 0x1F
+
+// GBInterrupts represents the interrupt enable and request registers
+type GBInterrupts struct {
+	// Interrupt enable, 0xFFFF
+	ie byte
+	// Interrupt flag, 0xFF0F
+	iflag byte
+}
+
+// InitInterrupts sets the interrupt registers to their post-boot values
+// The boot ROM leaves a VBlank request behind
+func (gbints *GBInterrupts) InitInterrupts() {
+	gbints.ie = 0x00
+	gbints.iflag = 1 << VBlank
+}
+
+// Request sets source's bit in IF
+func (gbints *GBInterrupts) Request(source int) {
+	gbints.iflag |= 1 << uint(source)
+}
+
+// Acknowledge clears source's bit in IF, which the CPU does when it jumps
+// to the interrupt's handler
+func (gbints *GBInterrupts) Acknowledge(source int) {
+	gbints.iflag &^= 1 << uint(source)
+}
+
+// Pending returns the interrupts that are both requested and enabled
+func (gbints *GBInterrupts) Pending() byte {
+	return gbints.ie & gbints.iflag & sourceMask
+}
+
+// Next returns the highest priority pending interrupt
+// Returns false if there isn't one
+func (gbints *GBInterrupts) Next() (int, bool) {
+	pending := gbints.Pending()
+	for source := VBlank; source <= Joypad; source++ {
+		if pending&(1<<uint(source)) != 0 {
+			return source, true
+		}
+	}
+
+	return 0, false
+}
+
+// Vector returns the address of source's interrupt handler
+func Vector(source int) uint16 {
+	return 0x40 + uint16(source)*8
+}
+
+// ReadIF returns the interrupt flag register
+// Unused upper bits read as 1
+func (gbints *GBInterrupts) ReadIF() byte {
+	return gbints.iflag | ^byte(sourceMask)
+}
+
+// WriteIF sets the interrupt flag register
+// Games can request or cancel interrupts by writing here
+func (gbints *GBInterrupts) WriteIF(data byte) {
+	gbints.iflag = data & sourceMask
+}
+
+// ReadIE returns the interrupt enable register
+// All 8 bits can be written and read back, even though only 5 are used
+func (gbints *GBInterrupts) ReadIE() byte {
+	return gbints.ie
+}
+
+// WriteIE sets the interrupt enable register
+func (gbints *GBInterrupts) WriteIE(data byte) {
+	gbints.ie = data
+}
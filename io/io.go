@@ -4,6 +4,7 @@
 package io
 
 import (
+	"../interrupts"
 	"github.com/hajimehoshi/ebiten"
 )
 
@@ -21,6 +22,10 @@ type GBIO struct {
 	col     byte
 }
 
+// GbInterrupts injection from main.go
+// Used to request the joypad interrupt when a selected line goes low
+var GbInterrupts *interrupts.GBInterrupts
+
 // InitIO initializes the GBIO struct
 // Key values are set to 0x0F and column 0 is selected by default
 func (gbio *GBIO) InitIO() {
@@ -32,80 +37,112 @@ func (gbio *GBIO) InitIO() {
 // Determines which buttons were pressed for this frame, sets bytes in
 // buttons array accordingly
 func (gbio *GBIO) ReadInput() {
+	buttons := gbio.buttons
 	// Start button
 	if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-		gbio.buttons[0] &= 0x7
+		buttons[0] &= 0x7
 	} else {
-		gbio.buttons[0] |= 0x8
+		buttons[0] |= 0x8
 	}
 
 	// Select button
 	if ebiten.IsKeyPressed(ebiten.KeyShift) {
-		gbio.buttons[0] &= 0xB
+		buttons[0] &= 0xB
 	} else {
-		gbio.buttons[0] |= 0x5
+		buttons[0] |= 0x5
 	}
 
 	// B button
 	if ebiten.IsKeyPressed(ebiten.KeyZ) {
-		gbio.buttons[0] &= 0xD
+		buttons[0] &= 0xD
 	} else {
-		gbio.buttons[0] |= 0x2
+		buttons[0] |= 0x2
 	}
 
 	// A button
 	if ebiten.IsKeyPressed(ebiten.KeyX) {
-		gbio.buttons[0] &= 0xE
+		buttons[0] &= 0xE
 	} else {
-		gbio.buttons[0] |= 0x1
+		buttons[0] |= 0x1
 	}
 
 	// D-pad up
 	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		gbio.buttons[1] &= 0xB
+		buttons[1] &= 0xB
 	} else {
-		gbio.buttons[1] |= 0x4
+		buttons[1] |= 0x4
 	}
 
 	// D-pad down
 	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		gbio.buttons[1] &= 0x7
+		buttons[1] &= 0x7
 	} else {
-		gbio.buttons[1] |= 0x8
+		buttons[1] |= 0x8
 	}
 
 	// D-pad left
 	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		gbio.buttons[1] &= 0xD
+		buttons[1] &= 0xD
 	} else {
-		gbio.buttons[1] |= 0x2
+		buttons[1] |= 0x2
 	}
 
 	// D-pad right
 	if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		gbio.buttons[1] &= 0xE
+		buttons[1] &= 0xE
 	} else {
-		gbio.buttons[1] |= 0x1
+		buttons[1] |= 0x1
+	}
+
+	gbio.setButtons(buttons)
+}
+
+// setButtons updates which buttons are held down
+func (gbio *GBIO) setButtons(buttons [2]byte) {
+	prevLines := gbio.lines()
+	gbio.buttons = buttons
+	gbio.checkInterrupt(prevLines)
+}
+
+// checkInterrupt requests the joypad interrupt if any of the lines read
+// through 0xFF00 went from 1 to 0 since prevLines
+// Only buttons in a selected column pull a line low, so pressing a button
+// in a column that isn't selected doesn't request it
+func (gbio *GBIO) checkInterrupt(prevLines byte) {
+	if prevLines&^gbio.lines() != 0 {
+		GbInterrupts.Request(interrupts.Joypad)
 	}
 }
 
+// lines returns the 4 joypad lines read through 0xFF00
+// A selected column pulls the lines of its held buttons low, and the
+// lines are high when neither column is selected
+func (gbio *GBIO) lines() byte {
+	lines := byte(0x0F)
+	if gbio.col&0x20 == 0 {
+		lines &= gbio.buttons[0]
+	}
+	if gbio.col&0x10 == 0 {
+		lines &= gbio.buttons[1]
+	}
+
+	return lines & 0x0F
+}
+
 // SetCol sets the column for inputs we should return to the CPU
 // This is called when a write to 0xFF00 happens, handled by the MMU
-// Will either be set to 0 or 1
+// Bit 5 clear selects the buttons and bit 4 clear selects the d-pad
+// Selecting a column while one of its buttons is held pulls a line low,
+// which also requests the joypad interrupt
 func (gbio *GBIO) SetCol(data byte) {
+	prevLines := gbio.lines()
 	gbio.col = data & 0x30
+	gbio.checkInterrupt(prevLines)
 }
 
 // GetInput returns a byte representing which buttons were pressed for
 // this frame
-// The button returned is dependent on which column is selected
+// The buttons returned depend on which columns are selected
 func (gbio *GBIO) GetInput() byte {
-	switch gbio.col {
-	case 0x10:
-		return gbio.buttons[0]
-	case 0x20:
-		return gbio.buttons[1]
-	default:
-		return 0x00
-	}
+	return gbio.lines()
 }
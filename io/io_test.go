@@ -0,0 +1,85 @@
+package io
+
+import (
+	"testing"
+
+	"../interrupts"
+)
+
+// newTestIO returns a GBIO with no buttons held and the given columns
+// selected through 0xFF00, with no interrupts requested
+func newTestIO(col byte) *GBIO {
+	GbInterrupts = new(interrupts.GBInterrupts)
+
+	gbio := new(GBIO)
+	gbio.InitIO()
+	gbio.SetCol(col)
+
+	return gbio
+}
+
+// joypadRequested returns true if the joypad interrupt has been requested,
+// and clears it
+func joypadRequested() bool {
+	requested := GbInterrupts.ReadIF()&(1<<interrupts.Joypad) != 0
+	GbInterrupts.WriteIF(0)
+
+	return requested
+}
+
+func TestJoypadInterrupt(t *testing.T) {
+	// A is bit 0 of the buttons column, right is bit 0 of the d-pad's
+	pressA := [2]byte{0x0E, 0x0F}
+	pressRight := [2]byte{0x0F, 0x0E}
+	pressBoth := [2]byte{0x0E, 0x0E}
+	released := [2]byte{0x0F, 0x0F}
+
+	tests := []struct {
+		name      string
+		col       byte
+		held      [2]byte
+		pressed   [2]byte
+		requested bool
+	}{
+		{"press in selected column", 0x10, released, pressA, true},
+		{"press in other column", 0x20, released, pressA, false},
+		{"press with no column selected", 0x30, released, pressA, false},
+		{"press with both columns selected", 0x00, released, pressRight, true},
+		{"line already low", 0x00, pressA, pressBoth, false},
+		{"release", 0x10, pressA, released, false},
+	}
+
+	for _, test := range tests {
+		gbio := newTestIO(test.col)
+		gbio.setButtons(test.held)
+		joypadRequested()
+
+		gbio.setButtons(test.pressed)
+		if got := joypadRequested(); got != test.requested {
+			t.Errorf("%s: joypad interrupt requested is %t, expected %t", test.name, got, test.requested)
+		}
+	}
+}
+
+func TestJoypadInterruptOnSelect(t *testing.T) {
+	gbio := newTestIO(0x30)
+	gbio.setButtons([2]byte{0x0F, 0x07})
+	if joypadRequested() {
+		t.Errorf("joypad interrupt requested pressing down with no column selected")
+	}
+
+	// Selecting the buttons doesn't pull any line low
+	gbio.SetCol(0x10)
+	if joypadRequested() {
+		t.Errorf("joypad interrupt requested selecting the buttons")
+	}
+
+	// Selecting the d-pad while down is held pulls its line low
+	gbio.SetCol(0x20)
+	if !joypadRequested() {
+		t.Errorf("joypad interrupt not requested selecting the d-pad with down held")
+	}
+	if got := gbio.GetInput(); got != 0x07 {
+		t.Errorf("0xFF00 reads $%02X with down held, expected $07", got)
+	}
+}
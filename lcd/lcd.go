@@ -6,39 +6,49 @@ import (
 	"image/color"
 	"image/draw"
 
+	"../cartridge"
 	"../cpu"
+	"../interrupts"
 	"../io"
 	"../mmu"
 	"../timer"
-	"github.com/hajimehoshi/ebiten"
 )
 
 // GBLCD represents the state of the LCD
 // mode: which mode the GB is in - VBlank, HBlank, OAM read, VRAM read
 // modeClock: clock cycle counter, changes which mode we're in
 // currentLine: what "scanline" is being drawn
-// Window: image of current window
+// View: the last finished frame
+// Palette: the colors shades are drawn with in View
 type GBLCD struct {
 	mode        uint8
 	modeClock   int16
 	currentLine uint16
 	View        image.Image
-}
+	Palette     Palette
 
-// Pixel holds color & x, y point
-type Pixel struct {
-	Point image.Point
-	Color color.RGBA
-}
+	// Lines are drawn into back as they finish, see render.go
+	// The buffers are swapped at VBlank, so front always holds a whole frame
+	back  *frame
+	front *frame
+
+	// Color indexes of the background and window on the line being drawn
+	// Sprites with the BG priority attribute only show over color 0
+	bgIndexes [screenWidth]byte
+
+	// Whether LY matched LYC on the last check, so the LYC=LY interrupt is
+	// only requested when they start matching
+	lyMatched bool
 
-// Sprite holds Tile data as a Pixel array & x, y point
-// x, y point is the upper-left corner of where to render the sprite
-// Tile is an array of pixels that make up actual data to draw to screen
-type Sprite struct {
-	Point image.Point
-	Tile  []*Pixel
+	// Window line counter, and whether LY has reached WY yet this frame
+	// Both are reset on line 0, see renderWindowLine
+	windowLine    int
+	windowReached bool
 }
 
+// Color of a CGB's screen while the CPU is stopped
+var stoppedCGBColor = color.RGBA{0, 0, 0, 255}
+
 // Constants for registers related to LCD
 const (
 	lcdc = 0xFF40
@@ -47,12 +57,17 @@ const (
 	scx  = 0xFF43
 	ly   = 0xFF44
 	lyc  = 0xFF45
+	wy   = 0xFF4A
+	wx   = 0xFF4B
 )
 
 // InitLCD sets LCD initial values
 // Only current one I'm aware of that we need nonzero is the mode
 func (gblcd *GBLCD) InitLCD() {
 	gblcd.mode = 2
+	gblcd.Palette = Palettes[DefaultPalette]
+	gblcd.back = new(frame)
+	gblcd.front = new(frame)
 }
 
 // Injected variables from main.go
@@ -61,6 +76,8 @@ var (
 	GbCPU   *cpu.GBCPU
 	GbTimer *timer.GBTimer
 	GbIO    *io.GBIO
+
+	GbInterrupts *interrupts.GBInterrupts
 )
 
 // lcdEnabled returns 1 if LCD is enabled, 0 if not
@@ -83,7 +100,7 @@ func (gblcd *GBLCD) getTileMap(identifier byte) []byte {
 
 	if useAltbgmap {
 		// Change background map location if bit above was set
-		return GbMMU.Memory[0x9C00:0x9FFF]
+		return GbMMU.Memory[0x9C00:0xA000]
 	}
 
 	return GbMMU.Memory[0x9800:0x9C00]
@@ -91,269 +108,58 @@ func (gblcd *GBLCD) getTileMap(identifier byte) []byte {
 
 // UpdateLCD updates the status of the LCD
 // First, checks if LCD is enabled. If not, set modeClock, currentLine, and LY
-// register value to 0. Also set LCD STAT register to default vlaue of 0x80.
+// register value to 0. Also clear the mode and coincidence bits of the LCD
+// STAT register, keeping the interrupt enables the game has set
 // Setting these values ensures that they don't get incremented when LCD is off
 // If it is enabled, then add to modeClock and set LCD status
-func (gblcd *GBLCD) UpdateLCD(cycles int, screen *ebiten.Image) {
+func (gblcd *GBLCD) UpdateLCD(cycles int) {
 	if gblcd.lcdEnabled() == 0 {
 		gblcd.modeClock = 0
 		gblcd.currentLine = 0
 		GbMMU.Memory[ly] = 0
+		gblcd.lyMatched = false
 
 		// Clear LCD status
-		GbMMU.Memory[stat] = 0x80
+		GbMMU.Memory[stat] = GbMMU.Memory[stat]&0x78 | 0x80
 	} else {
 		gblcd.modeClock += int16(cycles)
-		gblcd.setLCDStatus(screen)
-	}
-}
-
-// DrawFrame calls methods to construct and set the image of the current frame
-func (gblcd *GBLCD) DrawFrame() {
-	// Populate background map with Pixels, representing tiles
-	populatedBG := gblcd.populateTiles(false)
-
-	// Create image from populated background map
-	bgImage := gblcd.generateBackgroundImage(populatedBG)
-
-	gblcd.placeView(bgImage)
-}
-
-// populateBackgroundTiles takes a background map and returns an array
-// of 8x8 tiles, represented as Pixels
-// The BG map is really a list of tile identifiers - doesn't contain
-// actual tile data
-// This gets us the tile data from those identifiers
-func (gblcd *GBLCD) populateTiles(window bool) [][]*Pixel {
-	var bgmap []byte
-	var filledBackground [][]*Pixel
-
-	if window {
-		bgmap = gblcd.getTileMap(6)
-	} else {
-		bgmap = gblcd.getTileMap(3)
+		gblcd.setLCDStatus()
 	}
-
-	for _, tileID := range bgmap {
-		tile := gblcd.renderTile(int(tileID), 0, false)
-		filledBackground = append(filledBackground, tile)
-	}
-
-	return filledBackground
 }
 
-// generateBackgroundImage takes an array of Pixel arrays, each of which
-// represent an 8x8 tile, and returns an image
-func (gblcd *GBLCD) generateBackgroundImage(bgTiles [][]*Pixel) *image.RGBA {
-	background := image.NewRGBA(image.Rect(0, 0, 256, 256))
-
-	for i, tile := range bgTiles {
-		for _, px := range tile {
-			tileX := ((i % 32) * 8)
-			tileY := ((i / 32) * 8)
-			background.Set(px.Point.X+tileX, px.Point.Y+tileY, px.Color)
-		}
-	}
-
-	return background
+// Blank returns true if the screen shows nothing, which happens when the LCD
+// is turned off or the CPU is stopped
+func (gblcd *GBLCD) Blank() bool {
+	return gblcd.lcdEnabled() == 0 || GbCPU.Stopped
 }
 
-// placeView takes an image of the 256x256 background and creates the
-// 160x144 view into it
-// If the game has a window, it draws the portion of that window
-// that should be visible on top of the background before creating the final
-// view image
-func (gblcd *GBLCD) placeView(bgImage *image.RGBA) {
-	windowEnabled := GbMMU.Memory[lcdc]&(1<<5) != 0
-
-	// SCX and SCY specify the upper-left location on the 256x256 background map
-	// which is displayed on the upper-left corner of the LCD
-	// Basically, it is the window's offset into the background map
-	var yVal byte = GbMMU.Memory[scy]
-	var xVal byte = GbMMU.Memory[scx]
-
-	initialX := GbMMU.Memory[scx]
-
-	view := image.NewRGBA(image.Rect(0, 0, 160, 144))
-
-	for height := 0; height < 144; height++ {
-		for width := 0; width < 160; width++ {
-			offset := bgImage.PixOffset(int(xVal), int(yVal))
-			pix := bgImage.Pix[offset : offset+4]
-			color := color.RGBA{pix[0], pix[1], pix[2], pix[3]}
-			view.SetRGBA(width, height, color)
-
-			xVal++
-		}
-
-		yVal++
-		xVal = initialX
-	}
-
-	// TODO Should sprites be able to be drawn on top of window?
-	// Also this should probably pulled out into its own method
-	if windowEnabled {
-		populatedWindow := gblcd.populateTiles(true)
-		windowImage := gblcd.generateBackgroundImage(populatedWindow)
-
-		winX := int(GbMMU.Memory[0xFF4B]) - 7
-		winY := int(GbMMU.Memory[0xFF4A])
-		dp := image.Point{winX, winY}
-		windowBounds := windowImage.Bounds()
-
-		r := windowBounds.Sub(windowBounds.Min).Add(dp)
-		draw.Draw(view, r, windowImage, windowBounds.Min, draw.Src)
-	}
-
-	sprites := gblcd.renderSprites()
-
-	for _, sprite := range sprites {
-		for _, px := range sprite.Tile {
-			view.Set(px.Point.X+sprite.Point.X, px.Point.Y+sprite.Point.Y, px.Color)
-		}
-	}
-
-	// Update Window value with new frame data
+// DrawFrame sets View to the last frame finished at VBlank, colored with
+// Palette
+// A blank screen is filled with the lightest shade, except a stopped CGB
+// whose screen goes black
+func (gblcd *GBLCD) DrawFrame() {
+	view := image.NewRGBA(image.Rect(0, 0, screenWidth, screenHeight))
 	gblcd.View = view
-}
 
-// renderSprites goes through the OAM and populates a slice of Sprites
-// OAM always contains information about the Sprites currently on screen
-// TODO Handle additional sprite properties like rotation
-func (gblcd *GBLCD) renderSprites() []*Sprite {
-	var sprites []*Sprite
-	oam := GbMMU.Memory[0xFE00:0xFEA0]
-
-	for i := 0; i < len(oam); i += 4 {
-		// Get next sprite data
-		spriteData := GbMMU.Memory[0xFE00+i : 0xFE00+i+4]
-
-		yLoc := spriteData[0] - 16
-		xLoc := spriteData[1] - 8
-		attrs := spriteData[3]
-		tile := gblcd.renderTile(int(spriteData[2]), attrs, true)
-
-		s := &Sprite{
-			Point: image.Point{int(xLoc), int(yLoc)},
-			Tile:  tile,
+	if gblcd.Blank() {
+		fill := gblcd.Palette[0]
+		if GbCPU.Stopped && GbMMU.Header.Model() == cartridge.CGB {
+			fill = stoppedCGBColor
 		}
 
-		sprites = append(sprites, s)
-	}
-
-	return sprites
-}
-
-// renderTile returns a single tile as an array of Pixels
-// Takes a tile ID and a bool indicator of if this is a sprite or not
-// The tile ID is provided by the background map, and is used to calculate
-// the location of the tile data in memory
-func (gblcd *GBLCD) renderTile(tileID int, attrs byte, sprites bool) []*Pixel {
-	pixels := []*Pixel{}
-
-	// A pixel can be one of four "colors"
-	// In actual GB it is really black, white, or one of two grays
-	// The LCD itself causes the pale green we know and love
-	// These can be set to anything, but I set them to greenish to mimic the LCD
-	palette := [4]color.RGBA{
-		color.RGBA{205, 255, 205, 255},
-		color.RGBA{120, 170, 120, 255},
-		color.RGBA{35, 85, 35, 255},
-		color.RGBA{0, 0, 0, 255},
+		draw.Draw(view, view.Bounds(), image.NewUniform(fill), image.ZP, draw.Src)
+		return
 	}
 
-	// The 4th bit of the LCDC register determines where this tile is located
-	// in memory - one of two possible ranges
-	loTiles := GbMMU.Memory[lcdc]&(1<<4) != 0
-
-	// If the 4th bit was set, OR if we're rendering a sprite, our tile ID
-	// is unsigned (0 - 255) and we can find its data by
-	// adding the ID * 16 to the address 0x800
-	if loTiles || sprites {
-		tileID = 0x8000 + (tileID * 16)
-	} else {
-		// If we're in hi tiles set, tile locations are signed (-128 - +127)
-		if tileID > 127 {
-			// Calculate tile data location for a positive ID
-			tileID = tileID - 128
-			tileID = 0x8800 + (tileID * 16)
-		} else {
-			// Calculate the data location for a negative ID
-			tileID = 0x8800 + ((tileID + 128) * 16)
+	for y := range gblcd.front {
+		for x, shade := range gblcd.front[y] {
+			view.SetRGBA(x, y, gblcd.Palette[shade])
 		}
 	}
-
-	// A single 8x8 pixel tile is actually represented by 16 bytes
-	// A tile ID is really its beginning location in memory
-	// Its data is the location + next 16 bytes
-	tileVals := GbMMU.Memory[tileID : tileID+16]
-
-	// Here's where the calculation of a tile's pixel information happens
-	// It is somewhat convoluted, but https://fms.komkon.org/GameBoy/Tech/Software.html
-	// contains a good explanation in the "Video" section
-
-	// Iterate over lines of tiles, represented by 2 bytes
-	for line := 0; line < 8; line++ {
-		// Get 2 bytes representing current line
-		hi := tileVals[line*2]
-		lo := tileVals[line*2+1]
-
-		// Iterate over individual pixels of tile lines
-		for pix := 0; pix < 8; pix++ {
-			// TODO Maybe make color lookup more like hardware
-			// http://www.codeslinger.co.uk/pages/projects/gameboy/graphics.html
-
-			// Determine values of lo and hi bits for this pixel on the line
-			hiBit := (lo >> (7 - uint8(pix))) & 1
-			loBit := (hi >> (7 - uint8(pix))) & 1
-
-			// The result of this calculation is the index of a color in
-			// our palette
-			colorIndex := loBit + hiBit*2
-			color := palette[colorIndex]
-
-			// If we're rendering a sprite and the calculated colorIndex is 0,
-			// then this pixel of the sprite is simply not rendered
-			// This is how we get a "transluscent" effect on sprites
-			if sprites && colorIndex == 0 {
-				continue
-			}
-
-			var pixX, pixY int
-			if attrs&(1<<6) != 0 && attrs&(1<<5) != 0 {
-				pixX = 8 - pix
-				pixY = 8 - line
-			} else if attrs&(1<<6) != 0 {
-				// Y flip
-				pixX = pix
-				pixY = 8 - line
-			} else if attrs&(1<<5) != 0 {
-				// X flip
-				pixX = 8 - pix
-				pixY = line
-			} else {
-				// X location of pixel is the value of our current iterator
-				pixX = pix
-
-				// Y location of pixel is value of outer loop iterator
-				pixY = line
-			}
-
-			// Create new pixel
-			p := &Pixel{
-				Point: image.Point{pixX, pixY},
-				Color: color,
-			}
-
-			pixels = append(pixels, p)
-		}
-	}
-
-	return pixels
 }
 
 func (gblcd *GBLCD) setLCDInterrupt() {
-	GbMMU.Memory[0xFF0F] |= (1 << 1)
+	GbInterrupts.Request(interrupts.LCDStat)
 }
 
 // setLCDStatus changes the status of the LCD
@@ -362,7 +168,7 @@ func (gblcd *GBLCD) setLCDInterrupt() {
 // The GB hardware is actually meant to simulate a CRT in terms of timings
 // This is why we have HBlank/VBlank modes
 // Interrupt reference: http://www.emutalk.net/threads/41525-Game-Boy/page120
-func (gblcd *GBLCD) setLCDStatus(screen *ebiten.Image) {
+func (gblcd *GBLCD) setLCDStatus() {
 	switch gblcd.mode {
 	// Horizontal blanking mode
 	// GB is in this mode when horizontal lines are being drawn
@@ -376,13 +182,16 @@ func (gblcd *GBLCD) setLCDStatus(screen *ebiten.Image) {
 
 			// If we've rendered the last line on the LCD, enter VBlank mode
 			// and send a VBlank interrupt request
-			if gblcd.currentLine == 143 {
+			if gblcd.currentLine == screenHeight {
 				gblcd.mode = 1
+
+				// The frame is finished, so it can be shown
+				gblcd.front, gblcd.back = gblcd.back, gblcd.front
 				GbMMU.Memory[stat] |= (1 << 0)
 				GbMMU.Memory[stat] &^= (1 << 1)
 
 				// Request VBlank interrupt
-				GbMMU.Memory[0xFF0F] |= (1 << 0)
+				GbInterrupts.Request(interrupts.VBlank)
 
 				// Check for LCD STAT interrupt
 				if GbMMU.Memory[stat]&(1<<4) != 0 {
@@ -435,6 +244,7 @@ func (gblcd *GBLCD) setLCDStatus(screen *ebiten.Image) {
 	case 3:
 		if gblcd.modeClock >= 172 {
 			gblcd.modeClock = 0
+			gblcd.renderLine(int(gblcd.currentLine))
 			GbMMU.Memory[stat] &^= (1 << 0)
 			GbMMU.Memory[stat] &^= (1 << 1)
 			// Check for LCD STAT interrupt
@@ -447,18 +257,22 @@ func (gblcd *GBLCD) setLCDStatus(screen *ebiten.Image) {
 
 	// Check if LY and LYC registers are the same value
 	// If they are, we set the coincidence bit in the LCD STAT register
-	// and if LCD STAT interrupts are enabled, we send one
+	// and if LCD STAT interrupts are enabled, we send one when they start
+	// matching. This is checked every M-cycle, so the interrupt is only
+	// requested on the rising edge, not for as long as they match
 	// Otherwise clear the coincidence bit in the LCD STAT register
 	if GbMMU.Memory[ly] == GbMMU.Memory[lyc] {
 		// Set coincidence bit
 		GbMMU.Memory[stat] |= (1 << 2)
 
 		// LCD STAT interrupt
-		if GbMMU.Memory[stat]&(1<<6) != 0 {
+		if !gblcd.lyMatched && GbMMU.Memory[stat]&(1<<6) != 0 {
 			gblcd.setLCDInterrupt()
 		}
+		gblcd.lyMatched = true
 	} else {
 		// Clear coincidence bit
 		GbMMU.Memory[stat] &^= (1 << 2)
+		gblcd.lyMatched = false
 	}
 }
@@ -0,0 +1,53 @@
+package lcd
+
+import (
+	"testing"
+
+	"../interrupts"
+	"../mmu"
+)
+
+// newTestLCD returns an LCD in OAM read mode on line 0, with the LCD on and
+// no interrupts requested
+func newTestLCD() *GBLCD {
+	GbMMU = new(mmu.GBMMU)
+	GbMMU.InitMMU()
+	GbInterrupts = new(interrupts.GBInterrupts)
+	GbInterrupts.WriteIE(1 << interrupts.LCDStat)
+
+	gblcd := new(GBLCD)
+	gblcd.InitLCD()
+
+	return gblcd
+}
+
+func TestSTATWrite(t *testing.T) {
+	newTestLCD()
+
+	GbMMU.Memory[stat] = 0x85
+	GbMMU.WriteData(stat, 0x7A)
+	if got := GbMMU.ReadData(stat); got != 0xFD {
+		t.Errorf("STAT is $%02X after writing $7A, expected $FD", got)
+	}
+}
+
+func TestLYCInterruptRisingEdge(t *testing.T) {
+	gblcd := newTestLCD()
+	GbMMU.WriteData(stat, 1<<6)
+	GbMMU.Memory[lyc] = 0
+
+	// LY stays 0 for the whole of OAM read mode, which is checked every
+	// M-cycle
+	requests := 0
+	for i := 0; i < 10; i++ {
+		gblcd.UpdateLCD(4)
+		if GbInterrupts.Pending() != 0 {
+			requests++
+			GbInterrupts.Acknowledge(interrupts.LCDStat)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("LYC=LY interrupt requested %d times, expected 1", requests)
+	}
+}
@@ -0,0 +1,77 @@
+package lcd
+
+// The LCD works with 4 shades, 0 being the lightest and 3 the darkest
+// BGP, OBP0 and OBP1 map each color index in a tile to one of those shades,
+// which is what games change to fade the screen in and out or flash it
+// The frame only holds shades, what they look like is up to a Palette
+// Reference: http://gbdev.gg8.se/wiki/articles/Video_Display
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Constants for the palette registers
+const (
+	bgp  = 0xFF47
+	obp0 = 0xFF48
+	obp1 = 0xFF49
+)
+
+// applyPalette returns the shade the palette register at addr gives colorIndex
+// Each index has 2 bits in the register, index 0 in bits 0-1
+func applyPalette(addr int, colorIndex byte) byte {
+	return (GbMMU.Memory[addr] >> (colorIndex * 2)) & 3
+}
+
+// Palette holds the color each shade is drawn with, lightest first
+type Palette [4]color.RGBA
+
+// Palettes that can be picked by name
+// In actual GB shades are really black, white, or one of two grays
+// The LCD itself causes the pale green we know and love, so that's the default
+var Palettes = map[string]Palette{
+	"green": {
+		color.RGBA{205, 255, 205, 255},
+		color.RGBA{120, 170, 120, 255},
+		color.RGBA{35, 85, 35, 255},
+		color.RGBA{0, 0, 0, 255},
+	},
+	"gray": {
+		color.RGBA{255, 255, 255, 255},
+		color.RGBA{170, 170, 170, 255},
+		color.RGBA{85, 85, 85, 255},
+		color.RGBA{0, 0, 0, 255},
+	},
+}
+
+// DefaultPalette is used unless another one is picked
+const DefaultPalette = "green"
+
+// ParsePalette returns the palette named s, or made from s if it is 4 comma
+// separated hex colors, lightest first, like "FFFFFF,AAAAAA,555555,000000"
+func ParsePalette(s string) (Palette, error) {
+	if palette, ok := Palettes[s]; ok {
+		return palette, nil
+	}
+
+	var palette Palette
+	colors := strings.Split(s, ",")
+	if len(colors) != len(palette) {
+		return palette, fmt.Errorf("lcd: invalid palette %q, expected a name or 4 hex colors", s)
+	}
+
+	for i, c := range colors {
+		c = strings.TrimPrefix(strings.TrimSpace(c), "#")
+		rgb, err := strconv.ParseUint(c, 16, 24)
+		if err != nil || len(c) != 6 {
+			return palette, fmt.Errorf("lcd: invalid palette color %q, expected RRGGBB", c)
+		}
+
+		palette[i] = color.RGBA{byte(rgb >> 16), byte(rgb >> 8), byte(rgb), 255}
+	}
+
+	return palette, nil
+}
@@ -0,0 +1,106 @@
+package lcd
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParsePalette(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Palette
+		err      bool
+	}{
+		{s: "green", expected: Palettes["green"]},
+		{s: "gray", expected: Palettes["gray"]},
+		{
+			s: "FFFFFF,AAAAAA,555555,000000",
+			expected: Palette{
+				color.RGBA{255, 255, 255, 255},
+				color.RGBA{170, 170, 170, 255},
+				color.RGBA{85, 85, 85, 255},
+				color.RGBA{0, 0, 0, 255},
+			},
+		},
+		{
+			s: "#e0f8d0, #88c070, #346856, #081820",
+			expected: Palette{
+				color.RGBA{0xE0, 0xF8, 0xD0, 255},
+				color.RGBA{0x88, 0xC0, 0x70, 255},
+				color.RGBA{0x34, 0x68, 0x56, 255},
+				color.RGBA{0x08, 0x18, 0x20, 255},
+			},
+		},
+		{s: "", err: true},
+		{s: "blue", err: true},
+		{s: "FFFFFF,AAAAAA,555555", err: true},
+		{s: "FFFFFF,AAAAAA,555555,000000,000000", err: true},
+		{s: "FFFFFF,AAAAAA,555555,00000", err: true},
+		{s: "FFFFFF,AAAAAA,555555,0000000", err: true},
+		{s: "FFFFFF,AAAAAA,555555,GGGGGG", err: true},
+		{s: "FFFFFF,AAAAAA,555555,-00000", err: true},
+	}
+
+	for _, test := range tests {
+		palette, err := ParsePalette(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("%q: error is %v, expected an error to be %t", test.s, err, test.err)
+			continue
+		}
+		if err == nil && palette != test.expected {
+			t.Errorf("%q: palette is %v, expected %v", test.s, palette, test.expected)
+		}
+	}
+
+	if _, ok := Palettes[DefaultPalette]; !ok {
+		t.Errorf("default palette %q isn't in Palettes", DefaultPalette)
+	}
+}
+
+func TestApplyPalette(t *testing.T) {
+	newTestLCD()
+
+	// Each color index has 2 bits, index 0 in bits 0-1
+	GbMMU.Memory[bgp] = 0xE4
+	GbMMU.Memory[obp0] = 0x1B
+	GbMMU.Memory[obp1] = 0xD2
+
+	tests := []struct {
+		name     string
+		addr     int
+		expected [4]byte
+	}{
+		{"BGP", bgp, [4]byte{0, 1, 2, 3}},
+		{"OBP0", obp0, [4]byte{3, 2, 1, 0}},
+		{"OBP1", obp1, [4]byte{2, 0, 1, 3}},
+	}
+
+	for _, test := range tests {
+		for colorIndex, shade := range test.expected {
+			if got := applyPalette(test.addr, byte(colorIndex)); got != shade {
+				t.Errorf("%s maps color %d to shade %d, expected %d", test.name, colorIndex, got, shade)
+			}
+		}
+	}
+}
+
+// Sprites use OBP0 or OBP1 depending on their palette attribute, and the
+// background uses BGP
+func TestSpritePalettes(t *testing.T) {
+	gblcd := newRenderLCD(lcdcBG | lcdcSprites)
+	GbMMU.Memory[bgp] = 0xFF
+	GbMMU.Memory[obp0] = 0x04
+	GbMMU.Memory[obp1] = 0x08
+	fillTile(1, 1)
+	setSprite(0, 0, 0, 1, 0)
+	setSprite(1, 8, 0, 1, attrPalette)
+
+	gblcd.renderLine(0)
+
+	expected := map[int]byte{0: 1, 8: 2, 16: 3}
+	for x, shade := range expected {
+		if got := gblcd.back[0][x]; got != shade {
+			t.Errorf("shade at X %d is %d, expected %d", x, got, shade)
+		}
+	}
+}
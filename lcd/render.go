@@ -0,0 +1,253 @@
+package lcd
+
+// The screen is drawn a line at a time, at the end of mode 3 for each line
+// Games change registers like SCX, SCY and LCDC between lines for raster
+// effects like status bars and wavy intros, so each line has to be drawn
+// with the values the registers have at that moment
+// Reference: http://gbdev.gg8.se/wiki/articles/Video_Display
+
+import (
+	"sort"
+)
+
+// Screen size in pixels
+const (
+	screenWidth  = 160
+	screenHeight = 144
+)
+
+// frame holds the shade of each pixel on the screen, see palette.go
+type frame [screenHeight][screenWidth]byte
+
+// renderLine draws line of the screen into the back buffer
+func (gblcd *GBLCD) renderLine(line int) {
+	gblcd.renderBackgroundLine(line)
+	gblcd.renderWindowLine(line)
+	gblcd.renderSpritesLine(line)
+}
+
+// renderBackgroundLine draws the background's pixels for line
+// SCX and SCY specify the upper-left location on the 256x256 background map
+// which is displayed on the upper-left corner of the LCD. The map wraps
+// around at its edges
+// On DMG, clearing LCDC bit 0 blanks the background to color 0, so sprites
+// are drawn over it whatever their BG priority
+func (gblcd *GBLCD) renderBackgroundLine(line int) {
+	if !gblcd.bgEnabled() {
+		for x := 0; x < screenWidth; x++ {
+			gblcd.bgIndexes[x] = 0
+			gblcd.back[line][x] = applyPalette(bgp, 0)
+		}
+		return
+	}
+
+	bgmap := gblcd.getTileMap(3)
+	y := byte(line) + GbMMU.Memory[scy]
+	scrollX := GbMMU.Memory[scx]
+
+	for x := 0; x < screenWidth; x++ {
+		bgX := byte(x) + scrollX
+		tileID := bgmap[int(y/8)*32+int(bgX/8)]
+		colorIndex := gblcd.tilePixel(gblcd.bgTileAddr(tileID), int(y%8), int(bgX%8))
+		gblcd.bgIndexes[x] = colorIndex
+		gblcd.back[line][x] = applyPalette(bgp, colorIndex)
+	}
+}
+
+// renderWindowLine draws the window's pixels for line, if it covers the line
+// The window's upper-left corner is at (WX-7, WY), and it covers everything
+// to the right of that. It only shows up once LY has been equal to WY during
+// the frame, and from then on it's drawn on every line where LCDC bit 5 is set
+// and WX is on screen
+// Clearing LCDC bit 0 hides the window along with the background on DMG
+// The window has its own line counter, which only moves on lines where the
+// window was drawn. Turning it off for a few lines, like games do to put a
+// status bar at the top, carries on from the window line it stopped at
+// rather than skipping ahead
+func (gblcd *GBLCD) renderWindowLine(line int) {
+	if line == 0 {
+		gblcd.windowLine = 0
+		gblcd.windowReached = false
+	}
+	if line == int(GbMMU.Memory[wy]) {
+		gblcd.windowReached = true
+	}
+
+	// WX 0-6 puts the left of the window off screen, while 166 and up put it
+	// entirely past the right edge
+	// WX = 166 has glitchy effects on the next line on hardware, which games
+	// don't rely on, so it's treated as off screen too
+	windowEnabled := gblcd.bgEnabled() && GbMMU.Memory[lcdc]&(1<<5) != 0
+	winX := int(GbMMU.Memory[wx]) - 7
+	if !windowEnabled || !gblcd.windowReached || winX >= screenWidth-1 {
+		return
+	}
+
+	winmap := gblcd.getTileMap(6)
+	y := gblcd.windowLine
+
+	for x := winX; x < screenWidth; x++ {
+		if x < 0 {
+			continue
+		}
+
+		mapX := x - winX
+		tileID := winmap[(y/8)*32+mapX/8]
+		colorIndex := gblcd.tilePixel(gblcd.bgTileAddr(tileID), y%8, mapX%8)
+		gblcd.bgIndexes[x] = colorIndex
+		gblcd.back[line][x] = applyPalette(bgp, colorIndex)
+	}
+
+	gblcd.windowLine++
+}
+
+// bgEnabled returns true if LCDC bit 0 is set, which shows the background
+// and window
+func (gblcd *GBLCD) bgEnabled() bool {
+	return GbMMU.Memory[lcdc]&(1<<0) != 0
+}
+
+// Most sprites the hardware can draw on a single line
+const maxSpritesPerLine = 10
+
+// Sprite attribute bits, the 4th byte of a sprite's OAM entry
+const (
+	attrBGPriority = 1 << 7 // Drawn behind background colors 1-3
+	attrYFlip      = 1 << 6
+	attrXFlip      = 1 << 5
+	attrPalette    = 1 << 4 // Uses OBP1 instead of OBP0
+)
+
+// sprite is an OAM entry
+type sprite struct {
+	// Screen position of the upper-left corner
+	// OAM holds these offset by (8, 16), so that sprites can be partially
+	// off screen to the top and left
+	x, y  int
+	tile  byte
+	attrs byte
+}
+
+// spriteHeight returns 16 if LCDC bit 2 selects 8x16 sprites, otherwise 8
+func (gblcd *GBLCD) spriteHeight() int {
+	if GbMMU.Memory[lcdc]&(1<<2) != 0 {
+		return 16
+	}
+
+	return 8
+}
+
+// spritesOnLine does the OAM scan for line
+// It returns the first 10 sprites in OAM that cover the line, whether or not
+// they're on screen horizontally, in the order they're drawn with: the
+// sprite furthest to the left wins, and OAM order breaks ties
+func (gblcd *GBLCD) spritesOnLine(line int) []sprite {
+	var sprites []sprite
+	height := gblcd.spriteHeight()
+
+	for i := 0; i < 40 && len(sprites) < maxSpritesPerLine; i++ {
+		entry := GbMMU.Memory[0xFE00+i*4 : 0xFE00+i*4+4]
+		s := sprite{
+			x:     int(entry[1]) - 8,
+			y:     int(entry[0]) - 16,
+			tile:  entry[2],
+			attrs: entry[3],
+		}
+
+		if line >= s.y && line < s.y+height {
+			sprites = append(sprites, s)
+		}
+	}
+
+	sort.SliceStable(sprites, func(i, j int) bool {
+		return sprites[i].x < sprites[j].x
+	})
+
+	return sprites
+}
+
+// renderSpritesLine draws the sprites covering line
+// Sprites are 8x8, or 8x16 when LCDC bit 2 is set
+// Only the highest priority sprite with a non-transparent pixel at a given X
+// decides what's drawn there. If it has the BG priority attribute, it's
+// hidden behind background and window colors 1-3, and the sprites below it
+// don't show through
+func (gblcd *GBLCD) renderSpritesLine(line int) {
+	if GbMMU.Memory[lcdc]&(1<<1) == 0 {
+		return
+	}
+
+	sprites := gblcd.spritesOnLine(line)
+	height := gblcd.spriteHeight()
+
+	for x := 0; x < screenWidth; x++ {
+		for _, s := range sprites {
+			col := x - s.x
+			if col < 0 || col >= 8 {
+				continue
+			}
+
+			// Flipping an 8x16 sprite vertically also swaps its two tiles
+			row := line - s.y
+			if s.attrs&attrYFlip != 0 {
+				row = height - 1 - row
+			}
+			if s.attrs&attrXFlip != 0 {
+				col = 7 - col
+			}
+
+			// 8x16 sprites use tile n & 0xFE on top, and n | 0x01 below it
+			// Tiles are consecutive in memory, so rows 8-15 run straight
+			// into the bottom tile
+			tile := s.tile
+			if height == 16 {
+				tile &= 0xFE
+			}
+
+			// Color 0 is transparent for sprites, so whatever is below
+			// shows through
+			colorIndex := gblcd.tilePixel(0x8000+int(tile)*16, row, col)
+			if colorIndex == 0 {
+				continue
+			}
+
+			if s.attrs&attrBGPriority == 0 || gblcd.bgIndexes[x] == 0 {
+				palette := obp0
+				if s.attrs&attrPalette != 0 {
+					palette = obp1
+				}
+				gblcd.back[line][x] = applyPalette(palette, colorIndex)
+			}
+			break
+		}
+	}
+}
+
+// bgTileAddr returns the address of the data of a background or window tile
+// The 4th bit of the LCDC register determines where this tile is located
+// in memory - one of two possible ranges
+func (gblcd *GBLCD) bgTileAddr(tileID byte) int {
+	// If the 4th bit was set, our tile ID is unsigned (0 - 255) and we can
+	// find its data by adding the ID * 16 to the address 0x8000
+	if GbMMU.Memory[lcdc]&(1<<4) != 0 {
+		return 0x8000 + int(tileID)*16
+	}
+
+	// Otherwise tile IDs are signed (-128 - +127), relative to 0x9000
+	return 0x9000 + int(int8(tileID))*16
+}
+
+// tilePixel returns the color index of the pixel at row, col of the tile
+// whose data starts at addr
+// A single 8x8 pixel tile is represented by 16 bytes, 2 for each row
+// The first byte holds the low bit of each pixel's color index, and the
+// second holds the high bit. The leftmost pixel is in bit 7
+// https://fms.komkon.org/GameBoy/Tech/Software.html contains a good
+// explanation in the "Video" section
+func (gblcd *GBLCD) tilePixel(addr, row, col int) byte {
+	lo := GbMMU.Memory[addr+row*2]
+	hi := GbMMU.Memory[addr+row*2+1]
+	bit := uint(7 - col)
+
+	return (lo>>bit)&1 | ((hi>>bit)&1)<<1
+}
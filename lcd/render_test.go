@@ -0,0 +1,357 @@
+package lcd
+
+import (
+	"testing"
+)
+
+// LCDC bits used by the rendering tests
+const (
+	lcdcBG          = 1 << 0
+	lcdcSprites     = 1 << 1
+	lcdcTall        = 1 << 2
+	lcdcTileData    = 1 << 4
+	lcdcWindow      = 1 << 5
+	lcdcOn          = 1 << 7
+	identityPalette = 0xE4
+)
+
+// newRenderLCD returns an LCD set up to render with LCDC, using tile data at
+// 0x8000 and identity palettes, so each shade drawn is the color index
+// VRAM and OAM are empty, so every tile is color 0 and every sprite is off
+// screen
+func newRenderLCD(lcdcBits byte) *GBLCD {
+	gblcd := newTestLCD()
+	for addr := 0x8000; addr < 0xA000; addr++ {
+		GbMMU.Memory[addr] = 0
+	}
+	for addr := 0xFE00; addr < 0xFEA0; addr++ {
+		GbMMU.Memory[addr] = 0
+	}
+
+	GbMMU.Memory[lcdc] = lcdcOn | lcdcTileData | lcdcBits
+	GbMMU.Memory[bgp] = identityPalette
+	GbMMU.Memory[obp0] = identityPalette
+	GbMMU.Memory[obp1] = identityPalette
+	GbMMU.Memory[scx] = 0
+	GbMMU.Memory[scy] = 0
+	GbMMU.Memory[wx] = 0
+	GbMMU.Memory[wy] = 0
+
+	return gblcd
+}
+
+// fillTile sets every pixel of tile in 0x8000-0x8FFF to colorIndex
+func fillTile(tile int, colorIndex byte) {
+	var lo, hi byte
+	if colorIndex&1 != 0 {
+		lo = 0xFF
+	}
+	if colorIndex&2 != 0 {
+		hi = 0xFF
+	}
+
+	for row := 0; row < 8; row++ {
+		GbMMU.Memory[0x8000+tile*16+row*2] = lo
+		GbMMU.Memory[0x8000+tile*16+row*2+1] = hi
+	}
+}
+
+// setSprite sets OAM entry i to draw tile with its upper-left corner at x, y
+// on screen
+func setSprite(i, x, y int, tile, attrs byte) {
+	entry := GbMMU.Memory[0xFE00+i*4 : 0xFE00+i*4+4]
+	entry[0] = byte(y + 16)
+	entry[1] = byte(x + 8)
+	entry[2] = tile
+	entry[3] = attrs
+}
+
+// On DMG, LCDC bit 0 blanks the background and window to color 0, and
+// sprites show over them even with the BG priority attribute
+func TestBGDisabled(t *testing.T) {
+	tests := []struct {
+		name string
+		bits byte
+		// Shades expected under the sprite, and where only the background
+		// or window would be
+		sprite, bg byte
+	}{
+		{"BG on", lcdcBG | lcdcSprites | lcdcWindow, 3, 3},
+		{"BG off", lcdcSprites | lcdcWindow, 1, 0},
+	}
+
+	for _, test := range tests {
+		gblcd := newRenderLCD(test.bits)
+
+		// Tile 0 fills the background and window maps with color 3
+		fillTile(0, 3)
+		fillTile(1, 1)
+		setSprite(0, 0, 0, 1, attrBGPriority)
+		GbMMU.Memory[wx] = 7 + 80
+
+		gblcd.renderLine(0)
+
+		if got := gblcd.back[0][0]; got != test.sprite {
+			t.Errorf("%s: shade under the sprite is %d, expected %d", test.name, got, test.sprite)
+		}
+		if got := gblcd.back[0][40]; got != test.bg {
+			t.Errorf("%s: background shade is %d, expected %d", test.name, got, test.bg)
+		}
+		if got := gblcd.back[0][120]; got != test.bg {
+			t.Errorf("%s: window shade is %d, expected %d", test.name, got, test.bg)
+		}
+	}
+}
+
+// Only the first 10 sprites in OAM covering a line are drawn, even if some
+// of them are off screen horizontally
+func TestSpriteLimit(t *testing.T) {
+	gblcd := newRenderLCD(lcdcBG | lcdcSprites)
+	fillTile(1, 1)
+
+	// The first sprite is off screen to the left but still counts
+	setSprite(0, -8, 0, 1, 0)
+	for i := 1; i < 12; i++ {
+		setSprite(i, i*12, 0, 1, 0)
+	}
+
+	sprites := gblcd.spritesOnLine(0)
+	if len(sprites) != maxSpritesPerLine {
+		t.Fatalf("%d sprites on the line, expected %d", len(sprites), maxSpritesPerLine)
+	}
+
+	gblcd.renderLine(0)
+	for i := 1; i < 12; i++ {
+		expected := byte(1)
+		if i >= maxSpritesPerLine {
+			expected = 0
+		}
+		if got := gblcd.back[0][i*12]; got != expected {
+			t.Errorf("sprite %d drew shade %d, expected %d", i, got, expected)
+		}
+	}
+
+	// Sprites on other lines don't count towards the limit
+	setSprite(1, 12, 20, 1, 0)
+	if got := len(gblcd.spritesOnLine(0)); got != maxSpritesPerLine {
+		t.Errorf("%d sprites on the line after moving one away, expected %d", got, maxSpritesPerLine)
+	}
+	if sprites := gblcd.spritesOnLine(20); len(sprites) != 1 || sprites[0].x != 12 {
+		t.Errorf("sprites on line 20 are %v, expected only the one moved there", sprites)
+	}
+}
+
+// The sprite furthest to the left is drawn on top, and sprites at the same X
+// keep their OAM order
+func TestSpritePriority(t *testing.T) {
+	gblcd := newRenderLCD(lcdcBG | lcdcSprites)
+	fillTile(1, 1)
+	fillTile(2, 2)
+	fillTile(3, 3)
+
+	setSprite(0, 14, 0, 3, 0)
+	setSprite(1, 10, 0, 1, 0)
+	setSprite(2, 10, 0, 2, 0)
+
+	sprites := gblcd.spritesOnLine(0)
+	var tiles []byte
+	for _, s := range sprites {
+		tiles = append(tiles, s.tile)
+	}
+	if string(tiles) != string([]byte{1, 2, 3}) {
+		t.Errorf("sprites are ordered with tiles %v, expected [1 2 3]", tiles)
+	}
+
+	gblcd.renderLine(0)
+	expected := map[int]byte{
+		9:  0,
+		10: 1, // Sprite 1 wins over sprite 2 at the same X
+		17: 1, // Sprite 1 is further left than sprite 0
+		18: 3, // Sprite 1 has ended
+	}
+	for x, shade := range expected {
+		if got := gblcd.back[0][x]; got != shade {
+			t.Errorf("shade at X %d is %d, expected %d", x, got, shade)
+		}
+	}
+}
+
+// 8x16 sprites ignore bit 0 of their tile, drawing tile n & 0xFE on top and
+// n | 0x01 below it. Flipping vertically swaps the two
+func TestTallSprites(t *testing.T) {
+	tests := []struct {
+		name  string
+		tile  byte
+		attrs byte
+		// Shades expected on the top and bottom halves
+		top, bottom byte
+	}{
+		{"even tile", 4, 0, 1, 2},
+		{"odd tile", 5, 0, 1, 2},
+		{"Y flip", 5, attrYFlip, 2, 1},
+	}
+
+	for _, test := range tests {
+		gblcd := newRenderLCD(lcdcBG | lcdcSprites | lcdcTall)
+		fillTile(4, 1)
+		fillTile(5, 2)
+		setSprite(0, 0, 0, test.tile, test.attrs)
+
+		for line := 0; line < 17; line++ {
+			gblcd.renderLine(line)
+		}
+
+		if got := gblcd.back[0][0]; got != test.top {
+			t.Errorf("%s: top row shade is %d, expected %d", test.name, got, test.top)
+		}
+		if got := gblcd.back[7][0]; got != test.top {
+			t.Errorf("%s: row 7 shade is %d, expected %d", test.name, got, test.top)
+		}
+		if got := gblcd.back[8][0]; got != test.bottom {
+			t.Errorf("%s: row 8 shade is %d, expected %d", test.name, got, test.bottom)
+		}
+		if got := gblcd.back[15][0]; got != test.bottom {
+			t.Errorf("%s: bottom row shade is %d, expected %d", test.name, got, test.bottom)
+		}
+		if got := gblcd.back[16][0]; got != 0 {
+			t.Errorf("%s: shade below the sprite is %d, expected 0", test.name, got)
+		}
+	}
+}
+
+// Y flip works on rows within an 8x16 sprite, not just within each tile
+func TestTallSpriteYFlipRows(t *testing.T) {
+	gblcd := newRenderLCD(lcdcBG | lcdcSprites | lcdcTall)
+
+	// Only the very first row of the top tile has color 3
+	GbMMU.Memory[0x8000+6*16] = 0xFF
+	GbMMU.Memory[0x8000+6*16+1] = 0xFF
+	setSprite(0, 0, 0, 6, attrYFlip)
+
+	for line := 0; line < 16; line++ {
+		gblcd.renderLine(line)
+	}
+
+	for line := 0; line < 16; line++ {
+		expected := byte(0)
+		if line == 15 {
+			expected = 3
+		}
+		if got := gblcd.back[line][0]; got != expected {
+			t.Errorf("shade on line %d is %d, expected %d", line, got, expected)
+		}
+	}
+}
+
+// newWindowLCD returns an LCD with the window enabled, whose first row of
+// tiles is color 1 and second row is color 2. The background is color 0
+func newWindowLCD() *GBLCD {
+	// The background uses the map at 0x9C00, and the window the one at 0x9800
+	gblcd := newRenderLCD(lcdcBG | lcdcWindow | 1<<3)
+	fillTile(1, 1)
+	fillTile(2, 2)
+	for i := 0; i < 32; i++ {
+		GbMMU.Memory[0x9800+i] = 1
+		GbMMU.Memory[0x9800+32+i] = 2
+	}
+	GbMMU.Memory[wx] = 7
+
+	return gblcd
+}
+
+// The window line counter only moves on lines the window is drawn on, so
+// hiding it for a few lines carries on where it left off
+func TestWindowLineCounter(t *testing.T) {
+	tests := []struct {
+		name string
+		hide func()
+		show func()
+	}{
+		{
+			"LCDC bit 5",
+			func() { GbMMU.Memory[lcdc] &^= lcdcWindow },
+			func() { GbMMU.Memory[lcdc] |= lcdcWindow },
+		},
+		{
+			"WX 166",
+			func() { GbMMU.Memory[wx] = 166 },
+			func() { GbMMU.Memory[wx] = 7 },
+		},
+	}
+
+	for _, test := range tests {
+		gblcd := newWindowLCD()
+
+		// Hidden for lines 4-9, so window line 8 is drawn on line 14
+		for line := 0; line < 20; line++ {
+			if line == 4 {
+				test.hide()
+			}
+			if line == 10 {
+				test.show()
+			}
+			gblcd.renderLine(line)
+		}
+
+		expected := map[int]byte{0: 1, 3: 1, 4: 0, 9: 0, 10: 1, 13: 1, 14: 2, 19: 2}
+		for line, shade := range expected {
+			if got := gblcd.back[line][0]; got != shade {
+				t.Errorf("%s: shade on line %d is %d, expected %d", test.name, line, got, shade)
+			}
+		}
+	}
+}
+
+// The window only shows up once LY has reached WY, starting from its first
+// line, and the counter starts over each frame
+func TestWindowStart(t *testing.T) {
+	gblcd := newWindowLCD()
+	GbMMU.Memory[wy] = 10
+
+	for frame := 0; frame < 2; frame++ {
+		for line := 0; line < 20; line++ {
+			gblcd.renderLine(line)
+		}
+
+		expected := map[int]byte{0: 0, 9: 0, 10: 1, 17: 1, 18: 2}
+		for line, shade := range expected {
+			if got := gblcd.back[line][0]; got != shade {
+				t.Errorf("frame %d: shade on line %d is %d, expected %d", frame, line, got, shade)
+			}
+		}
+	}
+}
+
+// WX 7 puts the window's left edge at X 0, and up to 165 leaves part of it on
+// screen
+func TestWindowX(t *testing.T) {
+	tests := []struct {
+		wx byte
+		// First X the window covers, or screenWidth if it's hidden
+		left int
+	}{
+		{0, 0},
+		{7, 0},
+		{87, 80},
+		{165, 158},
+		{166, screenWidth},
+		{255, screenWidth},
+	}
+
+	for _, test := range tests {
+		gblcd := newWindowLCD()
+		GbMMU.Memory[wx] = test.wx
+		gblcd.renderLine(0)
+
+		for x := 0; x < screenWidth; x++ {
+			expected := byte(0)
+			if x >= test.left {
+				expected = 1
+			}
+			if got := gblcd.back[0][x]; got != expected {
+				t.Errorf("WX %d: shade at X %d is %d, expected %d", test.wx, x, got, expected)
+				break
+			}
+		}
+	}
+}
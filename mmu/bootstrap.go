@@ -1,10 +1,15 @@
 package mmu
 
+import (
+	"fmt"
+)
+
 // Dump from `xxd -p -u DMG_ROM.bin`
 // Checks cartridge header, scrolls Nintendo logo, and makes a familiar sound
 // CPU begins at address $0 on startup. This ROM exists from $0-$FF
 // CPU then reads from $100, the entry point for a cartridge
-// Not necessary, but good for testing
+// Not necessary, but good for testing, and gives us the exact power-on state
+// Run it by calling LoadBootROM before starting the CPU at 0x0000
 // More info & interesting history: http://gbdev.gg8.se/wiki/articles/Gameboy_Bootstrap_ROM
 var BootstrapROM [256]byte = [256]byte{
 	0x31, 0xFE, 0xFF, 0xAF, 0x21, 0xFF, 0x9F, 0x32, 0xCB, 0x7C, 0x20, 0xFB, 0x21, 0x26, 0xFF, 0x0E,
@@ -15,7 +20,7 @@ var BootstrapROM [256]byte = [256]byte{
 	0xF9, 0x2E, 0x0F, 0x18, 0xF3, 0x67, 0x3E, 0x64, 0x57, 0xE0, 0x42, 0x3E, 0x91, 0xE0, 0x40, 0x04,
 	0x1E, 0x02, 0x0E, 0x0C, 0xF0, 0x44, 0xFE, 0x90, 0x20, 0xFA, 0x0D, 0x20, 0xF7, 0x1D, 0x20, 0xF2,
 	0x0E, 0x13, 0x24, 0x7C, 0x1E, 0x83, 0xFE, 0x62, 0x28, 0x06, 0x1E, 0xC1, 0xFE, 0x64, 0x20, 0x06,
-	0x7B, 0xE2, 0x0C, 0x3E, 0x87, 0xF2, 0xF0, 0x42, 0x90, 0xE0, 0x42, 0x15, 0x20, 0xD2, 0x05, 0x20,
+	0x7B, 0xE2, 0x0C, 0x3E, 0x87, 0xE2, 0xF0, 0x42, 0x90, 0xE0, 0x42, 0x15, 0x20, 0xD2, 0x05, 0x20,
 	0x4F, 0x16, 0x20, 0x18, 0xCB, 0x4F, 0x06, 0x04, 0xC5, 0xCB, 0x11, 0x17, 0xC1, 0xCB, 0x11, 0x17,
 	0x05, 0x20, 0xF5, 0x22, 0x23, 0x22, 0x23, 0xC9, 0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B,
 	0x03, 0x73, 0x00, 0x83, 0x00, 0x0C, 0x00, 0x0D, 0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E,
@@ -24,3 +29,43 @@ var BootstrapROM [256]byte = [256]byte{
 	0x21, 0x04, 0x01, 0x11, 0xA8, 0x00, 0x1A, 0x13, 0xBE, 0x20, 0xFE, 0x23, 0x7D, 0xFE, 0x34, 0x20,
 	0xF5, 0x06, 0x19, 0x78, 0x86, 0x23, 0x05, 0x20, 0xFB, 0x86, 0x20, 0xFE, 0x3E, 0x01, 0xE0, 0x50,
 }
+
+// Boot ROM sizes
+// DMG and MGB boot ROMs cover 0x0000-0x00FF
+// CGB boot ROMs also cover 0x0200-0x08FF, leaving the cartridge header visible
+// at 0x0100-0x01FF so the boot ROM can read it
+const (
+	dmgBootROMSize = 0x100
+	cgbBootROMSize = 0x900
+)
+
+// LoadBootROM maps a boot ROM over the start of the cartridge
+// It stays mapped until the game writes to 0xFF50, which the boot ROM does
+// as its last instruction before jumping to the cartridge at 0x0100
+// I/O registers are cleared to their power-on state, since setting them up
+// is the boot ROM's job
+func (gbmmu *GBMMU) LoadBootROM(data []byte) error {
+	if len(data) != dmgBootROMSize && len(data) != cgbBootROMSize {
+		return fmt.Errorf("MMU: loadBootROM failed: %d bytes is not a DMG (%d) or CGB (%d) boot ROM",
+			len(data), dmgBootROMSize, cgbBootROMSize)
+	}
+
+	gbmmu.bootROM = data
+	gbmmu.bootROMMapped = true
+
+	for addr := 0xFF01; addr < 0xFF80; addr++ {
+		gbmmu.Memory[addr] = 0
+	}
+
+	return nil
+}
+
+// inBootROM returns true if addr should be read from the boot ROM
+func (gbmmu *GBMMU) inBootROM(addr uint16) bool {
+	if !gbmmu.bootROMMapped {
+		return false
+	}
+
+	return addr < dmgBootROMSize ||
+		(len(gbmmu.bootROM) == cgbBootROMSize && addr >= 0x0200 && addr < cgbBootROMSize)
+}
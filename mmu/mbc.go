@@ -0,0 +1,125 @@
+package mmu
+
+import (
+	"fmt"
+
+	"../cartridge"
+)
+
+// MBC represents a cartridge's memory bank controller
+// Cartridges bigger than 32KB can't fit into the GB's address space, so the
+// MBC sits between the CPU and the cartridge and decides which bank of ROM
+// and RAM is visible at a given time
+// Games switch banks by writing to addresses in the ROM area, which the MBC
+// intercepts as register writes
+// Reference: http://gbdev.gg8.se/wiki/articles/Memory_Bank_Controllers
+type MBC interface {
+	// ReadROM returns the byte at addr in 0x0000-0x7FFF
+	ReadROM(addr uint16) byte
+	// ROMBank returns the ROM bank mapped at addr in 0x0000-0x7FFF
+	ROMBank(addr uint16) int
+	// WriteROM handles writes to 0x0000-0x7FFF, which set MBC registers
+	WriteROM(addr uint16, data byte)
+	// ReadRAM returns the byte at addr in 0xA000-0xBFFF
+	ReadRAM(addr uint16) byte
+	// WriteRAM handles writes to 0xA000-0xBFFF
+	// Returns true if the byte was stored, rather than dropped because RAM
+	// is disabled or missing
+	WriteRAM(addr uint16, data byte) bool
+}
+
+// batteryBacked is implemented by MBCs whose RAM can be kept alive by a
+// battery in the cartridge, so it has to be saved to disk between sessions
+type batteryBacked interface {
+	saveData() []byte
+	loadSaveData(data []byte)
+}
+
+// clocked is implemented by MBCs with hardware that runs off the CPU clock
+// like the MBC3's real-time clock
+type clocked interface {
+	update(cycles int)
+}
+
+// newMBC returns the MBC for the cartridge type in the header
+// rumble is called when a rumble cartridge turns its motor on or off
+func newMBC(rom []byte, header *cartridge.Header, rumble func(on bool)) (MBC, error) {
+	switch header.Type.MBC() {
+	case cartridge.NoMBC:
+		return newROMOnly(rom, header.RAMSize), nil
+	case cartridge.MBC1:
+		return newMBC1(rom, header.RAMSize), nil
+	case cartridge.MBC2:
+		return newMBC2(rom), nil
+	case cartridge.MBC3:
+		return newMBC3(rom, header.RAMSize, header.Type.HasTimer()), nil
+	case cartridge.MBC5:
+		return newMBC5(rom, header.RAMSize, header.Type.HasRumble(), rumble), nil
+	default:
+		return nil, fmt.Errorf("unsupported cartridge type %s", header.Type)
+	}
+}
+
+// romOnly represents a cartridge with no MBC
+// These are at most 32KB and map directly into 0x0000-0x7FFF
+// Some may have up to 8KB of RAM at 0xA000-0xBFFF
+type romOnly struct {
+	rom []byte
+	ram []byte
+}
+
+func newROMOnly(rom []byte, ramSize int) *romOnly {
+	return &romOnly{
+		rom: rom,
+		ram: make([]byte, ramSize),
+	}
+}
+
+// ReadROM returns the byte at addr, or 0xFF past the end of the ROM
+func (ro *romOnly) ReadROM(addr uint16) byte {
+	if int(addr) >= len(ro.rom) {
+		return 0xFF
+	}
+
+	return ro.rom[addr]
+}
+
+// ROMBank returns 0 or 1, since the ROM is mapped as is
+func (ro *romOnly) ROMBank(addr uint16) int {
+	return int(addr / 0x4000)
+}
+
+// WriteROM is ignored since there are no registers to write to
+func (ro *romOnly) WriteROM(addr uint16, data byte) {}
+
+// ReadRAM returns the byte at addr, or 0xFF if there's no RAM there
+func (ro *romOnly) ReadRAM(addr uint16) byte {
+	offset := int(addr - 0xA000)
+	if offset >= len(ro.ram) {
+		return 0xFF
+	}
+
+	return ro.ram[offset]
+}
+
+// WriteRAM writes to cartridge RAM if it exists
+func (ro *romOnly) WriteRAM(addr uint16, data byte) bool {
+	offset := int(addr - 0xA000)
+	if offset >= len(ro.ram) {
+		return false
+	}
+
+	ro.ram[offset] = data
+
+	return true
+}
+
+// saveData returns a copy of cartridge RAM
+func (ro *romOnly) saveData() []byte {
+	return append([]byte{}, ro.ram...)
+}
+
+// loadSaveData restores cartridge RAM from a save file
+func (ro *romOnly) loadSaveData(data []byte) {
+	copy(ro.ram, data)
+}
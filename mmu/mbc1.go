@@ -0,0 +1,191 @@
+package mmu
+
+import (
+	"bytes"
+
+	"../cartridge"
+)
+
+// mbc1 represents the MBC1 memory bank controller
+// Supports up to 2MB of ROM (125 usable banks) and 32KB of RAM (4 banks)
+// Reference: http://gbdev.gg8.se/wiki/articles/MBC1
+type mbc1 struct {
+	rom []byte
+	ram []byte
+
+	// Set when 0x0A is written to 0x0000-0x1FFF
+	ramEnabled bool
+
+	// 5-bit ROM bank register written to 0x2000-0x3FFF
+	bank1 byte
+
+	// 2-bit register written to 0x4000-0x5FFF
+	// Selects the RAM bank, or the upper bits of the ROM bank
+	bank2 byte
+
+	// Banking mode written to 0x6000-0x7FFF
+	// Mode 0 only lets bank2 affect 0x4000-0x7FFF
+	// Mode 1 also lets it switch 0x0000-0x3FFF and the RAM bank
+	mode byte
+
+	// MBC1M multicarts wire bank2 to ROM bank bits 4-5 instead of 5-6
+	multicart bool
+}
+
+func newMBC1(rom []byte, ramSize int) *mbc1 {
+	return &mbc1{
+		rom:       rom,
+		ram:       make([]byte, ramSize),
+		bank1:     1,
+		multicart: isMulticart(rom),
+	}
+}
+
+// isMulticart detects MBC1M carts
+// There's no header value for these. They are always 1MB and contain a second
+// Nintendo logo at the start of bank 0x10, which is the first game's header
+func isMulticart(rom []byte) bool {
+	if len(rom) != 0x100000 {
+		return false
+	}
+
+	start := 0x10*0x4000 + 0x0104
+
+	return bytes.Equal(rom[start:start+len(cartridge.NintendoLogo)], cartridge.NintendoLogo[:])
+}
+
+// bankShift returns how far bank2 is shifted to form the upper ROM bank bits
+func (mbc *mbc1) bankShift() uint {
+	if mbc.multicart {
+		return 4
+	}
+
+	return 5
+}
+
+// romOffset returns the offset into rom of addr in the given bank
+// Bank numbers past the end of the ROM wrap, like the unconnected pins do
+// Every MBC maps ROM banks this way, only how the bank is picked differs
+func romOffset(rom []byte, bank int, addr uint16) int {
+	banks := len(rom) / 0x4000
+	if banks == 0 {
+		return int(addr)
+	}
+
+	return (bank%banks)*0x4000 + int(addr&0x3FFF)
+}
+
+// readROM returns the byte at addr in the given bank of rom, or 0xFF past
+// the end of the ROM
+func readROM(rom []byte, bank int, addr uint16) byte {
+	offset := romOffset(rom, bank, addr)
+	if offset >= len(rom) {
+		return 0xFF
+	}
+
+	return rom[offset]
+}
+
+// switchableBank returns the bank mapped at addr by MBCs which always have
+// bank 0 at 0x0000-0x3FFF, and bank at 0x4000-0x7FFF
+func switchableBank(bank int, addr uint16) int {
+	if addr < 0x4000 {
+		return 0
+	}
+
+	return bank
+}
+
+// mappedBank returns the bank selected by the registers for addr, before
+// wrapping at the end of the ROM
+func (mbc *mbc1) mappedBank(addr uint16) int {
+	var bank int
+
+	if addr < 0x4000 {
+		// Bank 0 area can only be switched in mode 1
+		if mbc.mode == 1 {
+			bank = int(mbc.bank2) << mbc.bankShift()
+		}
+	} else {
+		bank1 := mbc.bank1
+		if mbc.multicart {
+			// Multicarts don't wire bit 4 of bank1
+			bank1 &= 0x0F
+		}
+
+		bank = int(mbc.bank2)<<mbc.bankShift() | int(bank1)
+	}
+
+	return bank
+}
+
+// ROMBank returns the ROM bank mapped at addr
+func (mbc *mbc1) ROMBank(addr uint16) int {
+	return romOffset(mbc.rom, mbc.mappedBank(addr), addr) / 0x4000
+}
+
+// ReadROM returns the byte at addr from the currently mapped ROM bank
+func (mbc *mbc1) ReadROM(addr uint16) byte {
+	return readROM(mbc.rom, mbc.mappedBank(addr), addr)
+}
+
+// WriteROM sets MBC1 registers
+func (mbc *mbc1) WriteROM(addr uint16, data byte) {
+	switch {
+	case addr < 0x2000:
+		mbc.ramEnabled = data&0x0F == 0x0A
+	case addr < 0x4000:
+		// Bank 0 can't be selected in this register, so it becomes bank 1
+		// Only the 5-bit value is checked, which is why banks 0x20, 0x40
+		// and 0x60 are unreachable and map to 0x21, 0x41 and 0x61 instead
+		mbc.bank1 = data & 0x1F
+		if mbc.bank1 == 0 {
+			mbc.bank1 = 1
+		}
+	case addr < 0x6000:
+		mbc.bank2 = data & 0x03
+	default:
+		mbc.mode = data & 0x01
+	}
+}
+
+// ramOffset returns the offset into cartridge RAM for addr
+func (mbc *mbc1) ramOffset(addr uint16) int {
+	bank := 0
+	if mbc.mode == 1 {
+		bank = int(mbc.bank2)
+	}
+
+	return (bank*0x2000 + int(addr-0xA000)) % len(mbc.ram)
+}
+
+// ReadRAM returns the byte at addr from the current RAM bank
+// Disabled or missing RAM reads as 0xFF
+func (mbc *mbc1) ReadRAM(addr uint16) byte {
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return 0xFF
+	}
+
+	return mbc.ram[mbc.ramOffset(addr)]
+}
+
+// WriteRAM writes to the current RAM bank if RAM is enabled
+func (mbc *mbc1) WriteRAM(addr uint16, data byte) bool {
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return false
+	}
+
+	mbc.ram[mbc.ramOffset(addr)] = data
+
+	return true
+}
+
+// saveData returns a copy of cartridge RAM
+func (mbc *mbc1) saveData() []byte {
+	return append([]byte{}, mbc.ram...)
+}
+
+// loadSaveData restores cartridge RAM from a save file
+func (mbc *mbc1) loadSaveData(data []byte) {
+	copy(mbc.ram, data)
+}
@@ -0,0 +1,86 @@
+package mmu
+
+// mbc2 represents the MBC2 memory bank controller
+// Supports up to 256KB of ROM (16 banks) and has 512 x 4 bits of RAM built
+// into the MBC itself, so the header's RAM size is always 0
+// Reference: http://gbdev.gg8.se/wiki/articles/MBC2
+type mbc2 struct {
+	rom []byte
+
+	// Only the lower nibble of each byte is stored
+	ram [512]byte
+
+	// Set when 0x0A is written to 0x0000-0x3FFF with address bit 8 clear
+	ramEnabled bool
+
+	// 4-bit ROM bank number written to 0x0000-0x3FFF with address bit 8 set
+	romBank byte
+}
+
+func newMBC2(rom []byte) *mbc2 {
+	return &mbc2{
+		rom:     rom,
+		romBank: 1,
+	}
+}
+
+// ROMBank returns the ROM bank mapped at addr
+func (mbc *mbc2) ROMBank(addr uint16) int {
+	return romOffset(mbc.rom, switchableBank(int(mbc.romBank), addr), addr) / 0x4000
+}
+
+// ReadROM returns the byte at addr from the currently mapped ROM bank
+func (mbc *mbc2) ReadROM(addr uint16) byte {
+	return readROM(mbc.rom, switchableBank(int(mbc.romBank), addr), addr)
+}
+
+// WriteROM sets MBC2 registers
+// Both registers share 0x0000-0x3FFF, and bit 8 of the address decides
+// which one is written. Writes to 0x4000-0x7FFF do nothing
+func (mbc *mbc2) WriteROM(addr uint16, data byte) {
+	if addr >= 0x4000 {
+		return
+	}
+
+	if addr&0x0100 == 0 {
+		mbc.ramEnabled = data&0x0F == 0x0A
+	} else {
+		mbc.romBank = data & 0x0F
+		if mbc.romBank == 0 {
+			mbc.romBank = 1
+		}
+	}
+}
+
+// ReadRAM returns the nibble at addr with the upper 4 bits set
+// The 512 bytes are echoed across all of 0xA000-0xBFFF
+func (mbc *mbc2) ReadRAM(addr uint16) byte {
+	if !mbc.ramEnabled {
+		return 0xFF
+	}
+
+	return mbc.ram[addr&0x01FF] | 0xF0
+}
+
+// WriteRAM stores the lower nibble of data at addr
+func (mbc *mbc2) WriteRAM(addr uint16, data byte) bool {
+	if !mbc.ramEnabled {
+		return false
+	}
+
+	mbc.ram[addr&0x01FF] = data & 0x0F
+
+	return true
+}
+
+// saveData returns the 512 bytes of RAM, one nibble per byte
+func (mbc *mbc2) saveData() []byte {
+	return append([]byte{}, mbc.ram[:]...)
+}
+
+// loadSaveData restores RAM from a save file
+func (mbc *mbc2) loadSaveData(data []byte) {
+	for i := 0; i < len(mbc.ram) && i < len(data); i++ {
+		mbc.ram[i] = data[i] & 0x0F
+	}
+}
@@ -0,0 +1,159 @@
+package mmu
+
+import (
+	"time"
+)
+
+// mbc3 represents the MBC3 memory bank controller
+// Supports up to 2MB of ROM, 32KB of RAM (4 banks) and an optional
+// real-time clock, which is mapped in place of a RAM bank
+// Reference: http://gbdev.gg8.se/wiki/articles/MBC3
+type mbc3 struct {
+	rom []byte
+	ram []byte
+
+	// Set when 0x0A is written to 0x0000-0x1FFF
+	// Enables access to both RAM and the RTC registers
+	ramEnabled bool
+
+	// 7-bit ROM bank number written to 0x2000-0x3FFF
+	romBank byte
+
+	// Written to 0x4000-0x5FFF
+	// 0x00-0x03 selects a RAM bank, 0x08-0x0C selects an RTC register
+	ramBank byte
+
+	// Last value written to 0x6000-0x7FFF
+	// Writing 0x00 then 0x01 latches the clock
+	latchReg byte
+
+	// nil if the cartridge has no clock
+	rtc *gbRTC
+}
+
+func newMBC3(rom []byte, ramSize int, hasRTC bool) *mbc3 {
+	mbc := &mbc3{
+		rom:      rom,
+		ram:      make([]byte, ramSize),
+		romBank:  1,
+		latchReg: 0xFF,
+	}
+
+	if hasRTC {
+		mbc.rtc = new(gbRTC)
+	}
+
+	return mbc
+}
+
+// ROMBank returns the ROM bank mapped at addr
+func (mbc *mbc3) ROMBank(addr uint16) int {
+	return romOffset(mbc.rom, switchableBank(int(mbc.romBank), addr), addr) / 0x4000
+}
+
+// ReadROM returns the byte at addr from the currently mapped ROM bank
+func (mbc *mbc3) ReadROM(addr uint16) byte {
+	return readROM(mbc.rom, switchableBank(int(mbc.romBank), addr), addr)
+}
+
+// WriteROM sets MBC3 registers
+func (mbc *mbc3) WriteROM(addr uint16, data byte) {
+	switch {
+	case addr < 0x2000:
+		mbc.ramEnabled = data&0x0F == 0x0A
+	case addr < 0x4000:
+		// Unlike MBC1, all 7 bits are checked for bank 0
+		mbc.romBank = data & 0x7F
+		if mbc.romBank == 0 {
+			mbc.romBank = 1
+		}
+	case addr < 0x6000:
+		mbc.ramBank = data
+	default:
+		if mbc.rtc != nil && mbc.latchReg == 0x00 && data == 0x01 {
+			mbc.rtc.latch()
+		}
+		mbc.latchReg = data
+	}
+}
+
+// rtcSelected returns true if 0xA000-0xBFFF currently maps an RTC register
+func (mbc *mbc3) rtcSelected() bool {
+	return mbc.rtc != nil && mbc.ramBank >= 0x08 && mbc.ramBank <= 0x0C
+}
+
+// ramOffset returns the offset into cartridge RAM for addr
+func (mbc *mbc3) ramOffset(addr uint16) int {
+	return (int(mbc.ramBank&0x03)*0x2000 + int(addr-0xA000)) % len(mbc.ram)
+}
+
+// ReadRAM returns the byte at addr from the current RAM bank or RTC register
+func (mbc *mbc3) ReadRAM(addr uint16) byte {
+	if !mbc.ramEnabled {
+		return 0xFF
+	}
+
+	if mbc.rtcSelected() {
+		return mbc.rtc.read(mbc.ramBank)
+	}
+
+	if mbc.ramBank > 0x03 || len(mbc.ram) == 0 {
+		return 0xFF
+	}
+
+	return mbc.ram[mbc.ramOffset(addr)]
+}
+
+// WriteRAM writes to the current RAM bank or RTC register
+// The RTC registers are part of the save too, so writing them counts as
+// storing the byte
+func (mbc *mbc3) WriteRAM(addr uint16, data byte) bool {
+	if !mbc.ramEnabled {
+		return false
+	}
+
+	if mbc.rtcSelected() {
+		mbc.rtc.write(mbc.ramBank, data)
+		return true
+	}
+
+	if mbc.ramBank > 0x03 || len(mbc.ram) == 0 {
+		return false
+	}
+
+	mbc.ram[mbc.ramOffset(addr)] = data
+
+	return true
+}
+
+// update advances the RTC by the number of cycles the CPU ran
+func (mbc *mbc3) update(cycles int) {
+	if mbc.rtc != nil {
+		mbc.rtc.update(cycles)
+	}
+}
+
+// saveData returns cartridge RAM followed by the RTC footer, if any
+func (mbc *mbc3) saveData() []byte {
+	data := append([]byte{}, mbc.ram...)
+	if mbc.rtc != nil {
+		data = append(data, mbc.rtc.footer(time.Now())...)
+	}
+
+	return data
+}
+
+// loadSaveData restores cartridge RAM and the RTC from a save file
+// The RTC footer is optional, since some emulators don't write one
+func (mbc *mbc3) loadSaveData(data []byte) {
+	copy(mbc.ram, data)
+
+	if mbc.rtc == nil || len(data) <= len(mbc.ram) {
+		return
+	}
+
+	footer := data[len(mbc.ram):]
+	if len(footer) == rtcFooterSize || len(footer) == rtcFooterSizeShort {
+		mbc.rtc.loadFooter(footer, time.Now())
+	}
+}
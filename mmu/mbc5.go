@@ -0,0 +1,113 @@
+package mmu
+
+// mbc5 represents the MBC5 memory bank controller
+// Supports up to 8MB of ROM (512 banks) and 128KB of RAM (16 banks)
+// Rumble cartridges use bit 3 of the RAM bank register to drive a motor,
+// which leaves them with only 8 RAM banks
+// Reference: http://gbdev.gg8.se/wiki/articles/MBC5
+type mbc5 struct {
+	rom []byte
+	ram []byte
+
+	// Set when 0x0A is written to 0x0000-0x1FFF
+	ramEnabled bool
+
+	// 9-bit ROM bank number
+	// Lower 8 bits are written to 0x2000-0x2FFF, bit 8 to 0x3000-0x3FFF
+	romBank uint16
+
+	// 4-bit RAM bank number written to 0x4000-0x5FFF
+	ramBank byte
+
+	// Rumble cartridges only
+	hasRumble bool
+	motorOn   bool
+	rumble    func(on bool)
+}
+
+func newMBC5(rom []byte, ramSize int, hasRumble bool, rumble func(on bool)) *mbc5 {
+	return &mbc5{
+		rom:       rom,
+		ram:       make([]byte, ramSize),
+		romBank:   1,
+		hasRumble: hasRumble,
+		rumble:    rumble,
+	}
+}
+
+// ROMBank returns the ROM bank mapped at addr
+func (mbc *mbc5) ROMBank(addr uint16) int {
+	return romOffset(mbc.rom, switchableBank(int(mbc.romBank), addr), addr) / 0x4000
+}
+
+// ReadROM returns the byte at addr from the currently mapped ROM bank
+// Unlike other MBCs, bank 0 can be mapped to 0x4000-0x7FFF
+func (mbc *mbc5) ReadROM(addr uint16) byte {
+	return readROM(mbc.rom, switchableBank(int(mbc.romBank), addr), addr)
+}
+
+// WriteROM sets MBC5 registers
+func (mbc *mbc5) WriteROM(addr uint16, data byte) {
+	switch {
+	case addr < 0x2000:
+		mbc.ramEnabled = data&0x0F == 0x0A
+	case addr < 0x3000:
+		mbc.romBank = mbc.romBank&0x100 | uint16(data)
+	case addr < 0x4000:
+		mbc.romBank = uint16(data&0x01)<<8 | mbc.romBank&0xFF
+	case addr < 0x6000:
+		if mbc.hasRumble {
+			mbc.setMotor(data&0x08 != 0)
+			mbc.ramBank = data & 0x07
+		} else {
+			mbc.ramBank = data & 0x0F
+		}
+	}
+}
+
+// setMotor turns the rumble motor on or off, notifying the hook on changes
+func (mbc *mbc5) setMotor(on bool) {
+	if on == mbc.motorOn {
+		return
+	}
+
+	mbc.motorOn = on
+	if mbc.rumble != nil {
+		mbc.rumble(on)
+	}
+}
+
+// ramOffset returns the offset into cartridge RAM for addr
+func (mbc *mbc5) ramOffset(addr uint16) int {
+	return (int(mbc.ramBank)*0x2000 + int(addr-0xA000)) % len(mbc.ram)
+}
+
+// ReadRAM returns the byte at addr from the current RAM bank
+func (mbc *mbc5) ReadRAM(addr uint16) byte {
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return 0xFF
+	}
+
+	return mbc.ram[mbc.ramOffset(addr)]
+}
+
+// WriteRAM writes to the current RAM bank if RAM is enabled
+func (mbc *mbc5) WriteRAM(addr uint16, data byte) bool {
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return false
+	}
+
+	mbc.ram[mbc.ramOffset(addr)] = data
+
+	return true
+}
+
+// saveData returns a copy of cartridge RAM
+func (mbc *mbc5) saveData() []byte {
+	return append([]byte{}, mbc.ram...)
+}
+
+// loadSaveData restores cartridge RAM from a save file
+func (mbc *mbc5) loadSaveData(data []byte) {
+	copy(mbc.ram, data)
+}
@@ -0,0 +1,282 @@
+package mmu
+
+import (
+	"testing"
+)
+
+// regWrite is a write to an MBC register in 0x0000-0x7FFF
+type regWrite struct {
+	addr uint16
+	data byte
+}
+
+// bankedROM returns a ROM with the given number of 16KB banks, each starting
+// with its own bank number in little endian
+func bankedROM(banks int) []byte {
+	rom := make([]byte, banks*0x4000)
+	for bank := 0; bank < banks; bank++ {
+		rom[bank*0x4000] = byte(bank)
+		rom[bank*0x4000+1] = byte(bank >> 8)
+	}
+
+	return rom
+}
+
+// readBank returns the bank number stored at the start of the bank mapped at
+// addr, see bankedROM
+func readBank(mbc MBC, addr uint16) int {
+	return int(mbc.ReadROM(addr)) | int(mbc.ReadROM(addr+1))<<8
+}
+
+// multicartROM returns a 1MB MBC1M cartridge, which has a second header with
+// the Nintendo logo at the start of bank 0x10
+func multicartROM() []byte {
+	rom := bankedROM(64)
+	copy(rom[0x10*0x4000+0x0104:], BootstrapROM[0xA8:0xD8])
+
+	return rom
+}
+
+func TestMBC1ROMBanking(t *testing.T) {
+	tests := []struct {
+		name   string
+		rom    []byte
+		writes []regWrite
+		// Address read and the bank expected there
+		addr uint16
+		bank int
+	}{
+		{"bank 1 at start", bankedROM(128), nil, 0x4000, 1},
+		{"bank 5", bankedROM(128), []regWrite{{0x2000, 0x05}}, 0x4000, 5},
+		{"bank 0 is 1", bankedROM(128), []regWrite{{0x2000, 0x00}}, 0x4000, 1},
+		{"only 5 bits", bankedROM(128), []regWrite{{0x2000, 0xE3}}, 0x4000, 3},
+		{"wraps", bankedROM(8), []regWrite{{0x2000, 0x0B}}, 0x4000, 3},
+		{"upper bits", bankedROM(128), []regWrite{{0x4000, 0x02}, {0x2000, 0x05}}, 0x4000, 0x45},
+		{"bank 0x20 is 0x21", bankedROM(128), []regWrite{{0x4000, 0x01}, {0x2000, 0x00}}, 0x4000, 0x21},
+		{"bank 0x40 is 0x41", bankedROM(128), []regWrite{{0x4000, 0x02}, {0x2000, 0x00}}, 0x4000, 0x41},
+		{"bank 0x60 is 0x61", bankedROM(128), []regWrite{{0x4000, 0x03}, {0x2000, 0x00}}, 0x4000, 0x61},
+		{"mode 0 keeps bank 0", bankedROM(128), []regWrite{{0x4000, 0x02}}, 0x0000, 0},
+		{"mode 1 banks $0000", bankedROM(128), []regWrite{{0x4000, 0x02}, {0x6000, 0x01}}, 0x0000, 0x40},
+		{"mode 1 upper bits", bankedROM(128), []regWrite{{0x4000, 0x03}, {0x6000, 0x01}, {0x2000, 0x04}}, 0x4000, 0x64},
+		{"multicart upper bits", multicartROM(), []regWrite{{0x4000, 0x01}, {0x2000, 0x02}}, 0x4000, 0x12},
+		{"multicart drops bit 4", multicartROM(), []regWrite{{0x4000, 0x02}, {0x2000, 0x13}}, 0x4000, 0x23},
+		{"multicart mode 1", multicartROM(), []regWrite{{0x4000, 0x03}, {0x6000, 0x01}}, 0x0000, 0x30},
+		{"1MB without logo", bankedROM(64), []regWrite{{0x4000, 0x01}, {0x2000, 0x02}}, 0x4000, 0x22},
+	}
+
+	for _, test := range tests {
+		mbc := newMBC1(test.rom, 0)
+		for _, write := range test.writes {
+			mbc.WriteROM(write.addr, write.data)
+		}
+
+		if got := readBank(mbc, test.addr); got != test.bank {
+			t.Errorf("%s: read bank $%02X at $%04X, expected $%02X", test.name, got, test.addr, test.bank)
+		}
+	}
+}
+
+func TestMBC1Multicart(t *testing.T) {
+	if !isMulticart(multicartROM()) {
+		t.Errorf("1MB ROM with a second logo isn't detected as a multicart")
+	}
+	if isMulticart(bankedROM(64)) {
+		t.Errorf("1MB ROM without a second logo is detected as a multicart")
+	}
+
+	rom := append(multicartROM(), bankedROM(64)...)
+	if isMulticart(rom) {
+		t.Errorf("2MB ROM is detected as a multicart")
+	}
+}
+
+func TestMBC1RAMBanking(t *testing.T) {
+	mbc := newMBC1(bankedROM(8), 0x8000)
+
+	mbc.WriteRAM(0xA000, 0x11)
+	if got := mbc.ReadRAM(0xA000); got != 0xFF {
+		t.Errorf("disabled RAM reads $%02X, expected $FF", got)
+	}
+
+	mbc.WriteROM(0x0000, 0x0A)
+	mbc.WriteRAM(0xA000, 0x11)
+
+	// bank2 only selects the RAM bank in mode 1
+	mbc.WriteROM(0x4000, 0x02)
+	mbc.WriteRAM(0xA001, 0x22)
+	mbc.WriteROM(0x6000, 0x01)
+	mbc.WriteRAM(0xA000, 0x33)
+
+	if got := mbc.ram[0x0000]; got != 0x11 {
+		t.Errorf("bank 0 $A000 is $%02X, expected $11", got)
+	}
+	if got := mbc.ram[0x0001]; got != 0x22 {
+		t.Errorf("bank 0 $A001 is $%02X, expected $22 written in mode 0", got)
+	}
+	if got := mbc.ram[0x4000]; got != 0x33 {
+		t.Errorf("bank 2 $A000 is $%02X, expected $33", got)
+	}
+
+	mbc.WriteROM(0x6000, 0x00)
+	if got := mbc.ReadRAM(0xA000); got != 0x11 {
+		t.Errorf("$A000 reads $%02X back in mode 0, expected bank 0's $11", got)
+	}
+}
+
+func TestMBC5ROMBanking(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []regWrite
+		bank   int
+	}{
+		{"bank 1 at start", nil, 1},
+		{"bank 0", []regWrite{{0x2000, 0x00}}, 0},
+		{"all 8 bits", []regWrite{{0x2000, 0xFE}}, 0xFE},
+		{"9th bit", []regWrite{{0x2000, 0x05}, {0x3000, 0x01}}, 0x105},
+		{"9th bit kept", []regWrite{{0x3000, 0x01}, {0x2000, 0x10}}, 0x110},
+		{"only bit 0 of 9th bit", []regWrite{{0x3000, 0xFE}, {0x2000, 0x10}}, 0x10},
+		{"bank 0x100", []regWrite{{0x3000, 0x01}, {0x2000, 0x00}}, 0x100},
+	}
+
+	for _, test := range tests {
+		mbc := newMBC5(bankedROM(512), 0, false, nil)
+		for _, write := range test.writes {
+			mbc.WriteROM(write.addr, write.data)
+		}
+
+		if got := readBank(mbc, 0x4000); got != test.bank {
+			t.Errorf("%s: read bank $%03X at $4000, expected $%03X", test.name, got, test.bank)
+		}
+		if got := readBank(mbc, 0x0000); got != 0 {
+			t.Errorf("%s: read bank $%03X at $0000, expected 0", test.name, got)
+		}
+	}
+}
+
+func TestMBC5Rumble(t *testing.T) {
+	var changes []bool
+	mbc := newMBC5(bankedROM(4), 0x20000, true, func(on bool) {
+		changes = append(changes, on)
+	})
+	mbc.WriteROM(0x0000, 0x0A)
+
+	// Bit 3 drives the motor, so only 8 RAM banks are left
+	mbc.WriteROM(0x4000, 0x0B)
+	mbc.WriteRAM(0xA000, 0x55)
+	mbc.WriteROM(0x4000, 0x0B)
+	mbc.WriteROM(0x4000, 0x03)
+
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("motor changes are %v, expected [true false]", changes)
+	}
+	if got := mbc.ram[3*0x2000]; got != 0x55 {
+		t.Errorf("RAM bank 3 holds $%02X, expected $55", got)
+	}
+}
+
+func TestMBC2Registers(t *testing.T) {
+	tests := []struct {
+		name       string
+		writes     []regWrite
+		bank       int
+		ramEnabled bool
+	}{
+		{"bank 1 at start", nil, 1, false},
+		{"bit 8 set selects ROM bank", []regWrite{{0x0100, 0x05}}, 5, false},
+		{"bit 8 clear enables RAM", []regWrite{{0x0000, 0x0A}}, 1, true},
+		{"RAM enable doesn't switch banks", []regWrite{{0x0000, 0x05}}, 1, false},
+		{"ROM bank doesn't enable RAM", []regWrite{{0x0100, 0x0A}}, 10, false},
+		{"RAM disable", []regWrite{{0x0000, 0x0A}, {0x0000, 0x00}}, 1, false},
+		{"higher addresses", []regWrite{{0x3E00, 0x0A}, {0x2100, 0x03}}, 3, true},
+		{"only 4 bits", []regWrite{{0x0100, 0x1C}}, 12, false},
+		{"bank 0 is 1", []regWrite{{0x0100, 0x10}}, 1, false},
+		{"ignored above $3FFF", []regWrite{{0x4100, 0x05}}, 1, false},
+	}
+
+	for _, test := range tests {
+		mbc := newMBC2(bankedROM(16))
+		for _, write := range test.writes {
+			mbc.WriteROM(write.addr, write.data)
+		}
+
+		if got := readBank(mbc, 0x4000); got != test.bank {
+			t.Errorf("%s: read bank %d at $4000, expected %d", test.name, got, test.bank)
+		}
+		if mbc.ramEnabled != test.ramEnabled {
+			t.Errorf("%s: RAM enabled is %t, expected %t", test.name, mbc.ramEnabled, test.ramEnabled)
+		}
+	}
+}
+
+func TestMBC2RAM(t *testing.T) {
+	mbc := newMBC2(bankedROM(16))
+	mbc.WriteROM(0x0000, 0x0A)
+
+	// Only the lower nibble is stored, and the upper one reads as 1s
+	mbc.WriteRAM(0xA000, 0xAB)
+	if got := mbc.ReadRAM(0xA000); got != 0xFB {
+		t.Errorf("$A000 reads $%02X after writing $AB, expected $FB", got)
+	}
+
+	// 512 bytes are echoed across 0xA000-0xBFFF
+	mbc.WriteRAM(0xA3FF, 0x07)
+	if got := mbc.ReadRAM(0xA1FF); got != 0xF7 {
+		t.Errorf("$A1FF reads $%02X after writing $07 to $A3FF, expected $F7", got)
+	}
+	if got := mbc.ReadRAM(0xBE00); got != 0xFB {
+		t.Errorf("$BE00 reads $%02X, expected $A000's $FB", got)
+	}
+
+	data := mbc.saveData()
+	if len(data) != 512 || data[0] != 0x0B {
+		t.Errorf("save data is %d bytes starting with $%02X, expected 512 starting with $0B", len(data), data[0])
+	}
+
+	loaded := newMBC2(bankedROM(16))
+	loaded.loadSaveData([]byte{0xFC})
+	loaded.WriteROM(0x0000, 0x0A)
+	if got := loaded.ReadRAM(0xA000); got != 0xFC {
+		t.Errorf("$A000 reads $%02X after loading $FC, expected $FC", got)
+	}
+}
+
+func TestROMBank(t *testing.T) {
+	tests := []struct {
+		name string
+		mbc  MBC
+		// Register write that selects the bank
+		write regWrite
+		// Bank expected at 0x4000-0x7FFF
+		bank int
+	}{
+		{"ROM only", newROMOnly(bankedROM(2), 0), regWrite{0x2000, 0x05}, 1},
+		{"MBC1", newMBC1(bankedROM(8), 0), regWrite{0x2000, 0x05}, 5},
+		{"MBC1 wraps", newMBC1(bankedROM(8), 0), regWrite{0x2000, 0x0B}, 3},
+		{"MBC2", newMBC2(bankedROM(16)), regWrite{0x2100, 0x0C}, 12},
+		{"MBC2 bank 0 is 1", newMBC2(bankedROM(16)), regWrite{0x2100, 0x00}, 1},
+		{"MBC3", newMBC3(bankedROM(128), 0, false), regWrite{0x2000, 0x7F}, 127},
+		{"MBC3 wraps", newMBC3(bankedROM(64), 0, false), regWrite{0x2000, 0x45}, 5},
+		{"MBC5", newMBC5(bankedROM(512), 0, false, nil), regWrite{0x2000, 0xFE}, 254},
+		{"MBC5 bank 0", newMBC5(bankedROM(512), 0, false, nil), regWrite{0x2000, 0x00}, 0},
+	}
+
+	for _, test := range tests {
+		test.mbc.WriteROM(test.write.addr, test.write.data)
+
+		// ROMBank has to agree with the bank actually read
+		if got := readBank(test.mbc, 0x4000); got != test.bank {
+			t.Errorf("%s: read bank %d at $4000, expected %d", test.name, got, test.bank)
+		}
+		if got := test.mbc.ROMBank(0x7FFF); got != test.bank {
+			t.Errorf("%s: ROMBank($7FFF) is %d, expected %d", test.name, got, test.bank)
+		}
+		if got := test.mbc.ROMBank(0x3FFF); got != 0 {
+			t.Errorf("%s: ROMBank($3FFF) is %d, expected 0", test.name, got)
+		}
+	}
+
+	gbmmu := &GBMMU{mbc: newMBC1(bankedROM(8), 0)}
+	if got := gbmmu.ROMBank(0xC000); got != -1 {
+		t.Errorf("ROMBank($C000) is %d outside of ROM, expected -1", got)
+	}
+}
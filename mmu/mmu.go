@@ -9,46 +9,99 @@ package mmu
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"../apu"
+	"../cartridge"
+	"../interrupts"
 	"../io"
+	"../serial"
 )
 
 // GBMMU represents the Game Boy's memory
 // Reference http://gameboy.mongenel.com/dmg/asmmemmap.html
 type GBMMU struct {
 	// Array of bytes for contiguous memory access
+	// Cartridge ROM and RAM are not stored here, see mbc
 	Memory [65536]byte
+
+	// Memory bank controller of the loaded cartridge
+	// Handles all reads and writes to 0x0000-0x7FFF and 0xA000-0xBFFF
+	mbc MBC
+
+	// Header of the loaded cartridge, nil until LoadCart is called
+	Header *cartridge.Header
+
+	// Non-fatal problems found in the loaded cartridge's header
+	CartProblems []error
+
+	// Path of the save file for battery backed cartridges
+	// Empty if the cartridge has nothing to save
+	savePath string
+
+	// Cycles left until cartridge RAM is flushed to the save file
+	// Every byte stored in cartridge RAM restarts the countdown, so we save
+	// once the game is done writing rather than on every byte
+	saveDelay int
+
+	// Boot ROM overlaid on the cartridge at power on, see bootstrap.go
+	// Unmapped for good once the game writes to 0xFF50
+	bootROM       []byte
+	bootROMMapped bool
+
+	// Rumble is called when a rumble cartridge turns its motor on or off
+	// Frontends can set this to forward rumble to a gamepad
+	Rumble func(on bool)
+
+	// DisableSaves stops LoadCart from loading the save file, and cartridge
+	// RAM from being written to it, so every run starts from a clean cartridge
+	// Must be set before LoadCart is called
+	DisableSaves bool
+
+	// StubLY makes LY always read 0x90, the first line of VBlank
+	// Gameboy Doctor's reference logs are made this way, so that they don't
+	// depend on the LCD's timing
+	StubLY bool
 }
 
+// Number of cycles to wait after the last cartridge RAM write before saving
+// This is 3 seconds of emulated time
+const saveDelayCycles = 3 * 4194304
+
 // GbIO variable injection from main.go
 // Gives us access to instantiated IO struct's methods
 var GbIO *io.GBIO
 
+// GbSerial variable injection from main.go
+// Serial port registers are kept by the serial port itself
+var GbSerial *serial.GBSerial
+
+// GbAPU variable injection from main.go
+// Sound registers and wave RAM are kept by the APU itself
+var GbAPU *apu.GBAPU
+
+// GbInterrupts variable injection from main.go
+// IE and IF are kept by the interrupt controller
+var GbInterrupts *interrupts.GBInterrupts
+
+// ResetDivider injection from main.go
+// Called when the divider register is written, since the timer keeps the
+// counter behind it
+var ResetDivider func()
+
 // InitMMU sets initial memory values
-// These are actually populated by the Game Boy's bootstrap ROM
+// These are actually populated by the Game Boy's bootstrap ROM, which can be
+// run instead by calling LoadBootROM
 // Reference: http://bgb.bircd.org/pandocs.htm#powerupsequence
-// TODO load/execute bootstrap ROM instead?
 func (gbmmu *GBMMU) InitMMU() {
+	// No cartridge is inserted until LoadCart is called
+	gbmmu.mbc = newROMOnly(nil, 0)
+
 	// I/O register initial values after boot ROM
-	// TODO Might want to just execute boot ROM instead, since
-	// documentation online is sketchy about these
-	gbmmu.Memory[0xFF0F] = 0xE1
+	// Sound and interrupt registers are set by their own components
 	gbmmu.Memory[0xFF07] = 0xF8
-	gbmmu.Memory[0xFF10] = 0x80
-	gbmmu.Memory[0xFF11] = 0xBF
-	gbmmu.Memory[0xFF12] = 0xF3
-	gbmmu.Memory[0xFF14] = 0xBF
-	gbmmu.Memory[0xFF16] = 0x3F
-	gbmmu.Memory[0xFF19] = 0xBF
-	gbmmu.Memory[0xFF1A] = 0x7F
-	gbmmu.Memory[0xFF1B] = 0xFF
-	gbmmu.Memory[0xFF1C] = 0x9F
-	gbmmu.Memory[0xFF1E] = 0xBF
-	gbmmu.Memory[0xFF20] = 0xFF
-	gbmmu.Memory[0xFF23] = 0xBF
-	gbmmu.Memory[0xFF24] = 0x77
-	gbmmu.Memory[0xFF25] = 0xF3
-	gbmmu.Memory[0xFF26] = 0xF1
 	gbmmu.Memory[0xFF40] = 0x91
 	// Not in pandocs
 	gbmmu.Memory[0xFF41] = 0x85
@@ -65,20 +118,50 @@ func (gbmmu *GBMMU) InitMMU() {
 func (gbmmu *GBMMU) WriteData(addr uint16, data byte) {
 	if addr == 0xFF00 {
 		GbIO.SetCol(data)
+	} else if addr == 0xFF01 {
+		GbSerial.WriteSB(data)
+	} else if addr == 0xFF02 {
+		GbSerial.WriteSC(data)
+	} else if addr >= 0xFF10 && addr < 0xFF40 {
+		GbAPU.WriteRegister(addr, data)
 	} else if addr == 0xFF0F {
-		// TODO What do writes here really do? Ignored or bit set?
-		// gbmmu.Memory[0xFF0F] |= (1 << 0)
+		GbInterrupts.WriteIF(data)
+	} else if addr == 0xFFFF {
+		GbInterrupts.WriteIE(data)
 	} else if addr == 0xFF41 {
-		// TODO Same as above
-	} else if addr >= 0x0000 && addr <= 0x150 {
-		// Don't allow writes to invalid locations
+		// Only the STAT interrupt enables in bits 3-6 can be written
+		// The mode and coincidence bits are set by the LCD, bit 7 is unused
+		gbmmu.Memory[addr] = gbmmu.Memory[addr]&0x87 | data&0x78
+	} else if addr == 0xFF50 {
+		// Boot ROM disables itself by writing here, and can't be re-enabled
+		if data&0x01 != 0 {
+			gbmmu.bootROMMapped = false
+		}
+	} else if addr < 0x8000 {
+		// ROM can't be written to, but the MBC treats these as register writes
+		gbmmu.mbc.WriteROM(addr, data)
+	} else if addr >= 0xA000 && addr < 0xC000 {
+		// Writes while RAM is disabled don't change the save, so they
+		// don't need to start the countdown to saving it
+		stored := gbmmu.mbc.WriteRAM(addr, data)
+		if stored && gbmmu.savePath != "" {
+			gbmmu.saveDelay = saveDelayCycles
+		}
 	} else if addr == 0xFF46 {
-		spriteAddr := int(data) * 256
-		for i := range gbmmu.Memory[0xFE00:0xFEA0] {
-			gbmmu.Memory[0xFE00+i] = gbmmu.Memory[spriteAddr+i]
+		// DMA source may be in ROM or cartridge RAM, so read through the MBC
+		spriteAddr := uint16(data) << 8
+		for i := uint16(0); i < 0xA0; i++ {
+			gbmmu.Memory[0xFE00+i] = gbmmu.ReadData(spriteAddr + i)
+		}
+	} else if addr == 0xFF04 {
+		// Writing any value resets the divider
+		ResetDivider()
+	} else if addr == 0xFF4D {
+		// KEY1 only exists on CGB, and only its lowest bit can be written
+		// The current speed in bit 7 is changed by the STOP instruction
+		if gbmmu.cgbMode() {
+			gbmmu.Memory[addr] = gbmmu.Memory[addr]&0x80 | data&0x01
 		}
-	} else if addr == 0xFF07 {
-		gbmmu.Memory[addr] += data
 	} else {
 		gbmmu.Memory[addr] = data
 	}
@@ -92,30 +175,160 @@ func (gbmmu *GBMMU) WriteData(addr uint16, data byte) {
 func (gbmmu *GBMMU) ReadData(addr uint16) byte {
 	if addr == 0xFF00 {
 		return GbIO.GetInput()
+	} else if addr == 0xFF01 {
+		return GbSerial.ReadSB()
+	} else if addr == 0xFF02 {
+		return GbSerial.ReadSC()
+	} else if addr == 0xFF0F {
+		return GbInterrupts.ReadIF()
+	} else if addr == 0xFFFF {
+		return GbInterrupts.ReadIE()
+	} else if addr == 0xFF44 && gbmmu.StubLY {
+		return 0x90
+	} else if addr == 0xFF4D {
+		if !gbmmu.cgbMode() {
+			return 0xFF
+		}
+
+		return gbmmu.Memory[addr] | 0x7E
+	} else if addr >= 0xFF10 && addr < 0xFF40 {
+		return GbAPU.ReadRegister(addr)
+	} else if addr < 0x8000 {
+		if gbmmu.inBootROM(addr) {
+			return gbmmu.bootROM[addr]
+		}
+
+		return gbmmu.mbc.ReadROM(addr)
+	} else if addr >= 0xA000 && addr < 0xC000 {
+		return gbmmu.mbc.ReadRAM(addr)
 	}
 
 	return gbmmu.Memory[addr]
 }
 
-// LoadCart reads cartridge ROM into memory
-// Returns ROM as byte slice
+// LoadCart reads cartridge ROM and sets up its memory bank controller
+// The MBC and RAM size are chosen based on the parsed cartridge header
 func (gbmmu *GBMMU) LoadCart(path string) error {
 	cartData, err := ioutil.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("MMU: loadCart(%s) failed: %s", path, err)
 	}
 
-	// Cartridge header layout
-	// http://gbdev.gg8.se/wiki/articles/The_Cartridge_Header
-	for i, v := range cartData[0x0134:0x0143] {
-		gbmmu.Memory[0x0134+i] = v
+	header, err := cartridge.Parse(cartData)
+	if err != nil {
+		return fmt.Errorf("MMU: loadCart(%s) failed: %s", path, err)
+	}
+
+	mbc, err := newMBC(cartData, header, gbmmu.rumble)
+	if err != nil {
+		return fmt.Errorf("MMU: loadCart(%s) failed: %s", path, err)
+	}
+	gbmmu.mbc = mbc
+	gbmmu.Header = header
+
+	// Problems like bad checksums don't stop a game from running, so they
+	// are kept for the frontend to report instead of failing here
+	gbmmu.CartProblems = header.Verify(cartData)
+
+	// Battery backed RAM lives in a .sav file next to the ROM
+	// The file is a raw dump of cartridge RAM, followed by the RTC state for
+	// MBC3 carts, which is the same layout other emulators use
+	gbmmu.savePath = ""
+	gbmmu.saveDelay = 0
+	battery, ok := mbc.(batteryBacked)
+	if ok && header.Type.HasBattery() && !gbmmu.DisableSaves {
+		gbmmu.savePath = strings.TrimSuffix(path, filepath.Ext(path)) + ".sav"
+
+		saveData, err := ioutil.ReadFile(gbmmu.savePath)
+		if err == nil {
+			battery.loadSaveData(saveData)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("MMU: loadCart(%s) failed: %s", path, err)
+		}
+	}
+
+	return nil
+}
+
+// ROMBank returns the ROM bank mapped at addr, or -1 if addr isn't in ROM
+// The boot ROM doesn't count, addresses it covers are reported as bank 0
+func (gbmmu *GBMMU) ROMBank(addr uint16) int {
+	if addr >= 0x8000 {
+		return -1
+	}
+
+	return gbmmu.mbc.ROMBank(addr)
+}
+
+// SaveCart writes battery backed cartridge RAM to the save file
+// Does nothing if the cartridge doesn't have a battery
+// The data is written to a temporary file first and then renamed, so a crash
+// in the middle of saving can't leave behind a truncated save
+func (gbmmu *GBMMU) SaveCart() error {
+	battery, ok := gbmmu.mbc.(batteryBacked)
+	if !ok || gbmmu.savePath == "" {
+		return nil
+	}
+
+	gbmmu.saveDelay = 0
+
+	saveData := battery.saveData()
+	if len(saveData) == 0 {
+		return nil
+	}
+
+	tmpPath := gbmmu.savePath + ".tmp"
+	err := ioutil.WriteFile(tmpPath, saveData, 0644)
+	if err == nil {
+		err = os.Rename(tmpPath, gbmmu.savePath)
+	}
+
+	if err != nil {
+		return fmt.Errorf("MMU: saveCart(%s) failed: %s", gbmmu.savePath, err)
+	}
+
+	return nil
+}
+
+// cgbMode returns true if the loaded cartridge runs with CGB features
+func (gbmmu *GBMMU) cgbMode() bool {
+	return gbmmu.Header != nil && gbmmu.Header.Model() == cartridge.CGB
+}
+
+// SpeedSwitchArmed returns true if the game has requested a CGB speed switch
+// by setting bit 0 of KEY1 (0xFF4D). The switch happens on the next STOP
+func (gbmmu *GBMMU) SpeedSwitchArmed() bool {
+	return gbmmu.cgbMode() && gbmmu.Memory[0xFF4D]&0x01 != 0
+}
+
+// SwitchSpeed toggles the current speed bit in KEY1 and disarms the switch
+// Returns true if the CPU is now in double speed mode
+func (gbmmu *GBMMU) SwitchSpeed() bool {
+	gbmmu.Memory[0xFF4D] = (gbmmu.Memory[0xFF4D] ^ 0x80) & 0x80
+	return gbmmu.Memory[0xFF4D]&0x80 != 0
+}
+
+// rumble forwards the cartridge's motor state to the Rumble hook, if set
+func (gbmmu *GBMMU) rumble(on bool) {
+	if gbmmu.Rumble != nil {
+		gbmmu.Rumble(on)
+	}
+}
+
+// UpdateCart passes the cycles executed by the CPU to cartridge hardware
+// that keeps time, like the MBC3's real-time clock
+// Also flushes cartridge RAM to disk once the game stops writing to it
+func (gbmmu *GBMMU) UpdateCart(cycles int) error {
+	if cart, ok := gbmmu.mbc.(clocked); ok {
+		cart.update(cycles)
 	}
-	gbmmu.Memory[0x0143] = cartData[0x0143]
-	gbmmu.Memory[0x0147] = cartData[0x0147]
-	gbmmu.Memory[0x0148] = cartData[0x0148]
-	gbmmu.Memory[0x0149] = cartData[0x0149]
-	for i, v := range cartData[0x0000:] {
-		gbmmu.Memory[0x0000+i] = v
+
+	if gbmmu.saveDelay > 0 {
+		gbmmu.saveDelay -= cycles
+		if gbmmu.saveDelay <= 0 {
+			return gbmmu.SaveCart()
+		}
 	}
+
 	return nil
 }
@@ -0,0 +1,181 @@
+package mmu
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeBatteryCart writes a 32KB MBC1+RAM+BATTERY cartridge with 8KB of RAM
+// to a temporary directory, along with a save file holding save if it isn't
+// nil. Returns the path of the ROM
+func writeBatteryCart(t *testing.T, save []byte) string {
+	dir, err := ioutil.TempDir("", "halken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	rom := make([]byte, 0x8000)
+	rom[0x0147] = 0x03 // MBC1+RAM+BATTERY
+	rom[0x0149] = 0x02 // 8KB RAM
+
+	path := filepath.Join(dir, "battery.gb")
+	err = ioutil.WriteFile(path, rom, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if save != nil {
+		err = ioutil.WriteFile(filepath.Join(dir, "battery.sav"), save, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return path
+}
+
+// newTestMMU returns an MMU with the cartridge at path loaded and its RAM
+// enabled
+func newTestMMU(t *testing.T, path string) *GBMMU {
+	gbmmu := new(GBMMU)
+	gbmmu.InitMMU()
+
+	err := gbmmu.LoadCart(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gbmmu.WriteData(0x0000, 0x0A)
+
+	return gbmmu
+}
+
+func TestLoadSave(t *testing.T) {
+	save := make([]byte, 0x2000)
+	save[0x0000] = 0x12
+	save[0x1FFF] = 0x34
+	gbmmu := newTestMMU(t, writeBatteryCart(t, save))
+
+	if got := gbmmu.ReadData(0xA000); got != 0x12 {
+		t.Errorf("$A000 reads $%02X, expected $12 from the save", got)
+	}
+	if got := gbmmu.ReadData(0xBFFF); got != 0x34 {
+		t.Errorf("$BFFF reads $%02X, expected $34 from the save", got)
+	}
+}
+
+func TestLoadSaveSizeMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"short", 0x10},
+		{"long", 0x4000},
+	}
+
+	for _, test := range tests {
+		save := bytes.Repeat([]byte{0x5A}, test.size)
+		gbmmu := newTestMMU(t, writeBatteryCart(t, save))
+
+		// Whatever fits in cartridge RAM is loaded, the rest is left alone
+		if got := gbmmu.ReadData(0xA000); got != 0x5A {
+			t.Errorf("%s: $A000 reads $%02X, expected $5A from the save", test.name, got)
+		}
+		expected := byte(0x00)
+		if test.size > 0x2000 {
+			expected = 0x5A
+		}
+		if got := gbmmu.ReadData(0xBFFF); got != expected {
+			t.Errorf("%s: $BFFF reads $%02X, expected $%02X", test.name, got, expected)
+		}
+
+		err := gbmmu.SaveCart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, err := ioutil.ReadFile(gbmmu.savePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(saved) != 0x2000 {
+			t.Errorf("%s: saved %d bytes, expected the RAM size of %d", test.name, len(saved), 0x2000)
+		}
+	}
+}
+
+func TestSaveAfterDelay(t *testing.T) {
+	path := writeBatteryCart(t, nil)
+	gbmmu := newTestMMU(t, path)
+	gbmmu.WriteData(0xA010, 0x77)
+
+	err := gbmmu.UpdateCart(saveDelayCycles - 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(gbmmu.savePath); !os.IsNotExist(err) {
+		t.Fatalf("save file was written before the delay was up")
+	}
+
+	err = gbmmu.UpdateCart(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := ioutil.ReadFile(gbmmu.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 0x2000 || saved[0x10] != 0x77 {
+		t.Errorf("save file doesn't hold the byte written to $A010")
+	}
+
+	// The next run starts from the save
+	loaded := newTestMMU(t, path)
+	if got := loaded.ReadData(0xA010); got != 0x77 {
+		t.Errorf("$A010 reads $%02X after reloading, expected $77", got)
+	}
+}
+
+func TestSaveDelayOnlyWhenStored(t *testing.T) {
+	gbmmu := newTestMMU(t, writeBatteryCart(t, nil))
+	gbmmu.WriteData(0x0000, 0x00)
+
+	gbmmu.WriteData(0xA000, 0x77)
+	if gbmmu.saveDelay != 0 {
+		t.Errorf("write with RAM disabled started the save delay")
+	}
+
+	gbmmu.WriteData(0x0000, 0x0A)
+	gbmmu.WriteData(0xA000, 0x77)
+	if gbmmu.saveDelay != saveDelayCycles {
+		t.Errorf("save delay is %d after a write, expected %d", gbmmu.saveDelay, saveDelayCycles)
+	}
+}
+
+func TestDisableSaves(t *testing.T) {
+	save := make([]byte, 0x2000)
+	save[0] = 0x12
+	path := writeBatteryCart(t, save)
+
+	gbmmu := new(GBMMU)
+	gbmmu.InitMMU()
+	gbmmu.DisableSaves = true
+	err := gbmmu.LoadCart(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gbmmu.WriteData(0x0000, 0x0A)
+
+	if got := gbmmu.ReadData(0xA000); got != 0x00 {
+		t.Errorf("$A000 reads $%02X, expected the save not to be loaded", got)
+	}
+
+	gbmmu.WriteData(0xA000, 0x34)
+	if gbmmu.saveDelay != 0 {
+		t.Errorf("write started the save delay with saves disabled")
+	}
+}
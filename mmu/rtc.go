@@ -0,0 +1,181 @@
+package mmu
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// GB CPU runs at 4194304 cycles per second, which is what the RTC counts on
+const cyclesPerSecond = 4194304
+
+// Registers in the upper day counter byte
+const (
+	rtcDayHigh  = 1 << 0
+	rtcHalt     = 1 << 6
+	rtcDayCarry = 1 << 7
+)
+
+// Size of the RTC footer appended to .sav files
+// Layout is the one used by VBA-M, BGB and others:
+// 5 little endian uint32 values for the live S, M, H, DL, DH registers,
+// 5 more for the latched registers, then a 64-bit UNIX timestamp
+// Some emulators write a 32-bit timestamp instead, so 44 bytes is also valid
+const (
+	rtcFooterSize      = 48
+	rtcFooterSizeShort = 44
+)
+
+// rtcRegs holds the values of the 5 RTC registers, mapped at 0x08-0x0C
+// Index 0 is seconds, 1 minutes, 2 hours, 3 lower 8 bits of the day counter
+// and 4 is the day counter's upper bit along with halt and carry flags
+type rtcRegs [5]byte
+
+// gbRTC is the real-time clock found on MBC3 cartridges
+// It keeps counting while the GB is off thanks to the cartridge battery, which
+// we emulate by storing the time the game was saved and catching up on load
+// Reference: http://gbdev.gg8.se/wiki/articles/MBC3#The_Clock_Counter_Registers
+type gbRTC struct {
+	live    rtcRegs
+	latched rtcRegs
+
+	// Cycles since the seconds register last incremented
+	cycles int
+}
+
+// halted returns true if the halt flag is set, which stops the clock
+func (rtc *gbRTC) halted() bool {
+	return rtc.live[4]&rtcHalt != 0
+}
+
+// update advances the clock by the given number of CPU cycles
+func (rtc *gbRTC) update(cycles int) {
+	if rtc.halted() {
+		return
+	}
+
+	rtc.cycles += cycles
+	if rtc.cycles >= cyclesPerSecond {
+		rtc.advance(int64(rtc.cycles / cyclesPerSecond))
+		rtc.cycles %= cyclesPerSecond
+	}
+}
+
+// count adds n increments to a counter register
+// The counter carries into the next one when it reaches limit. A game may
+// write a value of limit or more, which then counts up to the bit width of
+// the register (wrap) and goes back to 0 without carrying
+// Returns the new value and the number of carries into the next counter
+func count(val byte, n int64, limit int64, wrap int64) (byte, int64) {
+	total := int64(val)
+	if total >= limit {
+		if n < wrap-total {
+			return byte(total + n), 0
+		}
+		n -= wrap - total
+		total = 0
+	}
+
+	total += n
+
+	return byte(total % limit), total / limit
+}
+
+// advance moves the clock forward by the given number of seconds
+func (rtc *gbRTC) advance(seconds int64) {
+	var carry int64
+	rtc.live[0], carry = count(rtc.live[0], seconds, 60, 64)
+	rtc.live[1], carry = count(rtc.live[1], carry, 60, 64)
+	rtc.live[2], carry = count(rtc.live[2], carry, 24, 32)
+
+	// Day counter is 9 bits wide, and sets the carry flag when it overflows
+	// The flag stays set until the game clears it
+	days := int64(rtc.live[4]&rtcDayHigh)<<8 | int64(rtc.live[3])
+	days += carry
+	if days >= 512 {
+		rtc.live[4] |= rtcDayCarry
+		days %= 512
+	}
+
+	rtc.live[3] = byte(days)
+	rtc.live[4] = rtc.live[4]&^rtcDayHigh | byte(days>>8)
+}
+
+// catchUp advances the clock by the wall-clock time since the game was saved
+func (rtc *gbRTC) catchUp(elapsed time.Duration) {
+	if rtc.halted() || elapsed <= 0 {
+		return
+	}
+
+	rtc.advance(int64(elapsed / time.Second))
+}
+
+// latch copies the live registers into the latched ones, which are what the
+// game actually reads
+func (rtc *gbRTC) latch() {
+	rtc.latched = rtc.live
+}
+
+// read returns the latched value of an RTC register
+// Unused bits read as 1
+func (rtc *gbRTC) read(reg byte) byte {
+	val := rtc.latched[reg-0x08]
+
+	switch reg {
+	case 0x08, 0x09:
+		return val | 0xC0
+	case 0x0A:
+		return val | 0xE0
+	case 0x0C:
+		return val | 0x3E
+	default:
+		return val
+	}
+}
+
+// write sets an RTC register
+// Writing the seconds register also resets the sub-second counter
+func (rtc *gbRTC) write(reg byte, data byte) {
+	switch reg {
+	case 0x08:
+		rtc.live[0] = data & 0x3F
+		rtc.cycles = 0
+	case 0x09:
+		rtc.live[1] = data & 0x3F
+	case 0x0A:
+		rtc.live[2] = data & 0x1F
+	case 0x0B:
+		rtc.live[3] = data
+	case 0x0C:
+		rtc.live[4] = data & (rtcDayHigh | rtcHalt | rtcDayCarry)
+	}
+}
+
+// footer serializes the RTC state with the given save time
+func (rtc *gbRTC) footer(now time.Time) []byte {
+	data := make([]byte, rtcFooterSize)
+
+	for i := 0; i < 5; i++ {
+		binary.LittleEndian.PutUint32(data[i*4:], uint32(rtc.live[i]))
+		binary.LittleEndian.PutUint32(data[20+i*4:], uint32(rtc.latched[i]))
+	}
+	binary.LittleEndian.PutUint64(data[40:], uint64(now.Unix()))
+
+	return data
+}
+
+// loadFooter restores the RTC state from a footer, then catches up to now
+func (rtc *gbRTC) loadFooter(data []byte, now time.Time) {
+	for i := 0; i < 5; i++ {
+		rtc.live[i] = byte(binary.LittleEndian.Uint32(data[i*4:]))
+		rtc.latched[i] = byte(binary.LittleEndian.Uint32(data[20+i*4:]))
+	}
+
+	var saved int64
+	if len(data) >= rtcFooterSize {
+		saved = int64(binary.LittleEndian.Uint64(data[40:]))
+	} else {
+		saved = int64(binary.LittleEndian.Uint32(data[40:]))
+	}
+
+	rtc.catchUp(now.Sub(time.Unix(saved, 0)))
+}
@@ -0,0 +1,183 @@
+package mmu
+
+import (
+	"testing"
+	"time"
+)
+
+// stepSecond moves regs forward one second the way the hardware does,
+// register by register, to check advance against
+func stepSecond(regs *rtcRegs) {
+	limits := []byte{60, 60, 24}
+	masks := []byte{0x3F, 0x3F, 0x1F}
+	for i := range limits {
+		regs[i] = (regs[i] + 1) & masks[i]
+		if regs[i] != limits[i] {
+			return
+		}
+		regs[i] = 0
+	}
+
+	regs[3]++
+	if regs[3] == 0 {
+		if regs[4]&rtcDayHigh != 0 {
+			regs[4] = regs[4]&^rtcDayHigh | rtcDayCarry
+		} else {
+			regs[4] |= rtcDayHigh
+		}
+	}
+}
+
+func TestRTCRollover(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    rtcRegs
+		seconds  int64
+		expected rtcRegs
+	}{
+		{"second", rtcRegs{0, 0, 0, 0, 0}, 1, rtcRegs{1, 0, 0, 0, 0}},
+		{"minute", rtcRegs{59, 0, 0, 0, 0}, 1, rtcRegs{0, 1, 0, 0, 0}},
+		{"hour", rtcRegs{59, 59, 0, 0, 0}, 1, rtcRegs{0, 0, 1, 0, 0}},
+		{"day", rtcRegs{59, 59, 23, 0, 0}, 1, rtcRegs{0, 0, 0, 1, 0}},
+		{"day high bit", rtcRegs{59, 59, 23, 255, 0}, 1, rtcRegs{0, 0, 0, 0, rtcDayHigh}},
+		{"day carry", rtcRegs{59, 59, 23, 255, rtcDayHigh}, 1, rtcRegs{0, 0, 0, 0, rtcDayCarry}},
+		{"carry stays set", rtcRegs{0, 0, 0, 0, rtcDayCarry}, 86400, rtcRegs{0, 0, 0, 1, rtcDayCarry}},
+		{"1000 days", rtcRegs{0, 0, 0, 0, 0}, 1000 * 86400, rtcRegs{0, 0, 0, 232, rtcDayHigh | rtcDayCarry}},
+		{"mixed", rtcRegs{30, 45, 22, 10, 0}, 2*86400 + 3*3600 + 20*60 + 40, rtcRegs{10, 6, 2, 13, 0}},
+		{"seconds out of range", rtcRegs{62, 5, 0, 0, 0}, 2, rtcRegs{0, 5, 0, 0, 0}},
+		{"minutes out of range", rtcRegs{59, 63, 0, 0, 0}, 1, rtcRegs{0, 0, 0, 0, 0}},
+		{"hours out of range", rtcRegs{59, 59, 31, 0, 0}, 1, rtcRegs{0, 0, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		rtc := &gbRTC{live: test.start}
+		rtc.advance(test.seconds)
+		if rtc.live != test.expected {
+			t.Errorf("%s: %v after %d seconds, expected %v", test.name, rtc.live, test.seconds, test.expected)
+		}
+	}
+}
+
+// Catching up at once has to end up where counting every second would
+func TestRTCAdvanceMatchesSteps(t *testing.T) {
+	starts := []rtcRegs{
+		{0, 0, 0, 0, 0},
+		{58, 59, 23, 255, rtcDayHigh},
+		{61, 62, 30, 7, 0},
+		{12, 63, 25, 200, rtcDayHigh},
+	}
+
+	for _, start := range starts {
+		for _, seconds := range []int64{1, 59, 61, 3599, 3601, 86399, 200000} {
+			rtc := &gbRTC{live: start}
+			rtc.advance(seconds)
+
+			expected := start
+			for i := int64(0); i < seconds; i++ {
+				stepSecond(&expected)
+			}
+
+			if rtc.live != expected {
+				t.Errorf("%v + %d seconds is %v, expected %v", start, seconds, rtc.live, expected)
+			}
+		}
+	}
+}
+
+func TestRTCUpdate(t *testing.T) {
+	rtc := new(gbRTC)
+
+	rtc.update(cyclesPerSecond - 4)
+	if rtc.live[0] != 0 {
+		t.Errorf("seconds is %d before a second has passed", rtc.live[0])
+	}
+
+	rtc.update(cyclesPerSecond*2 + 4)
+	if rtc.live[0] != 3 {
+		t.Errorf("seconds is %d after 3 seconds of cycles, expected 3", rtc.live[0])
+	}
+}
+
+func TestRTCHalt(t *testing.T) {
+	rtc := new(gbRTC)
+	rtc.write(0x08, 10)
+	rtc.write(0x0C, rtcHalt)
+
+	rtc.update(cyclesPerSecond * 5)
+	rtc.catchUp(time.Hour)
+	if rtc.live[0] != 10 || rtc.live[1] != 0 {
+		t.Errorf("halted clock moved to %v", rtc.live)
+	}
+
+	rtc.write(0x0C, 0)
+	rtc.catchUp(time.Minute)
+	if rtc.live[0] != 10 || rtc.live[1] != 1 {
+		t.Errorf("clock is %v a minute after being restarted, expected 1:10", rtc.live)
+	}
+}
+
+func TestRTCLatch(t *testing.T) {
+	mbc := newMBC3(bankedROM(2), 0, true)
+	mbc.WriteROM(0x0000, 0x0A)
+	mbc.WriteROM(0x4000, 0x08)
+	mbc.rtc.live[0] = 5
+
+	// Only writing 0x00 then 0x01 latches
+	mbc.WriteROM(0x6000, 0x01)
+	if got := mbc.ReadRAM(0xA000); got != 0xC0 {
+		t.Errorf("seconds reads $%02X after writing only $01, expected $C0", got)
+	}
+
+	mbc.WriteROM(0x6000, 0x00)
+	mbc.WriteROM(0x6000, 0x01)
+	if got := mbc.ReadRAM(0xA000); got != 0xC5 {
+		t.Errorf("seconds reads $%02X after latching, expected $C5", got)
+	}
+
+	// The latched value holds while the clock keeps counting
+	mbc.rtc.live[0] = 6
+	if got := mbc.ReadRAM(0xA000); got != 0xC5 {
+		t.Errorf("seconds reads $%02X without latching again, expected $C5", got)
+	}
+
+	mbc.WriteROM(0x6000, 0x02)
+	mbc.WriteROM(0x6000, 0x01)
+	if got := mbc.ReadRAM(0xA000); got != 0xC5 {
+		t.Errorf("seconds reads $%02X after writing $02 then $01, expected $C5", got)
+	}
+}
+
+func TestRTCFooter(t *testing.T) {
+	saved := time.Unix(1500000000, 0)
+
+	rtc := new(gbRTC)
+	rtc.live = rtcRegs{10, 20, 5, 100, rtcDayHigh}
+	rtc.latched = rtcRegs{1, 2, 3, 4, 0}
+	footer := rtc.footer(saved)
+	if len(footer) != rtcFooterSize {
+		t.Fatalf("footer is %d bytes, expected %d", len(footer), rtcFooterSize)
+	}
+
+	// The 44 byte footer has a 32-bit timestamp, which is the lower half of
+	// the little endian 64-bit one
+	tests := []struct {
+		name   string
+		footer []byte
+	}{
+		{"48 bytes", footer},
+		{"44 bytes", footer[:rtcFooterSizeShort]},
+	}
+
+	for _, test := range tests {
+		loaded := new(gbRTC)
+		loaded.loadFooter(test.footer, saved.Add(90*time.Second))
+
+		expected := rtcRegs{40, 21, 5, 100, rtcDayHigh}
+		if loaded.live != expected {
+			t.Errorf("%s: live registers are %v, expected %v", test.name, loaded.live, expected)
+		}
+		if loaded.latched != rtc.latched {
+			t.Errorf("%s: latched registers are %v, expected %v", test.name, loaded.latched, rtc.latched)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+// Rumble cartridges turn a motor on and off through their MBC, see
+// mmu/mbc5.go
+// This version of ebiten can't make gamepads vibrate, so the motor is only
+// tracked here. Headless mode reports how many times it was turned on
+
+import (
+	"fmt"
+)
+
+// Whether the rumble motor is on, and how many times it has been turned on
+var (
+	rumbleOn    bool
+	rumbleCount int
+)
+
+// rumble is the MMU's Rumble hook, called when the motor turns on or off
+func rumble(on bool) {
+	if on && !rumbleOn {
+		rumbleCount++
+	}
+	rumbleOn = on
+}
+
+// printRumble reports how many times the rumble motor was turned on, if the
+// game used it at all
+func printRumble() {
+	if rumbleCount == 0 {
+		return
+	}
+
+	fmt.Printf("headless: rumble motor turned on %d times\n", rumbleCount)
+}
@@ -0,0 +1,18 @@
+package serial
+
+import (
+	"io"
+)
+
+// Capture is a SerialPeer which writes every byte it receives to W
+// It never sends anything back, so the GB receives 0xFF like with nothing
+// connected. Blargg's test ROMs print their output this way
+type Capture struct {
+	W io.Writer
+}
+
+// Exchange writes the received byte to W and returns 0xFF
+func (capture *Capture) Exchange(out byte) byte {
+	capture.W.Write([]byte{out})
+	return 0xFF
+}
@@ -0,0 +1,134 @@
+// Package serial emulates the GB's serial port, used by the link cable
+// A transfer shifts the byte in SB out one bit at a time, while shifting in
+// the bits sent by the other side. After 8 bits, SB holds the received byte
+// and a serial interrupt is requested
+// Reference: http://gbdev.gg8.se/wiki/articles/Serial_Data_Transfer_(Link_Cable)
+package serial
+
+import (
+	"../interrupts"
+)
+
+// SC register bits
+const (
+	// Set to start a transfer, cleared by hardware when it completes
+	scTransfer = 1 << 7
+	// Set to use the internal clock, cleared to be clocked by the peer
+	scInternalClock = 1 << 0
+)
+
+// SerialPeer is the device on the other end of the link cable
+type SerialPeer interface {
+	// Exchange is called when a transfer clocked by the GB completes
+	// out is the byte the GB sent, and the return value is the byte the peer
+	// sent back at the same time
+	Exchange(out byte) byte
+}
+
+// GBSerial represents the serial port registers and transfer state
+type GBSerial struct {
+	// Serial transfer data, 0xFF01
+	sb byte
+	// Serial transfer control, 0xFF02
+	sc byte
+
+	// Bits shifted so far in the current transfer
+	bits int
+	// Byte being sent, SB gets shifted as the transfer goes on
+	out byte
+
+	// Peer is the connected device, nil if nothing is plugged in
+	// With nothing connected, all received bits are 1
+	Peer SerialPeer
+}
+
+// GbInterrupts injection from main.go
+// Used to request the serial interrupt when a transfer completes
+var GbInterrupts *interrupts.GBInterrupts
+
+// InitSerial sets the serial registers to their post-boot values
+func (gbserial *GBSerial) InitSerial() {
+	gbserial.sb = 0x00
+	gbserial.sc = 0x7E
+	gbserial.bits = 0
+}
+
+// ReadSB returns the value of the serial transfer data register
+func (gbserial *GBSerial) ReadSB() byte {
+	return gbserial.sb
+}
+
+// WriteSB sets the serial transfer data register
+func (gbserial *GBSerial) WriteSB(data byte) {
+	gbserial.sb = data
+}
+
+// ReadSC returns the value of the serial control register
+// Unused bits read as 1
+func (gbserial *GBSerial) ReadSC() byte {
+	return gbserial.sc | 0x7E
+}
+
+// WriteSC sets the serial control register
+// Setting bit 7 starts a transfer
+func (gbserial *GBSerial) WriteSC(data byte) {
+	gbserial.sc = data
+	gbserial.bits = 0
+	gbserial.out = gbserial.sb
+}
+
+// transferring returns true if a transfer is in progress
+func (gbserial *GBSerial) transferring() bool {
+	return gbserial.sc&scTransfer != 0
+}
+
+// Clock shifts one bit of a transfer using the internal clock
+// The timer calls this on each falling edge of bit 8 of its internal
+// counter, which is 8192Hz, so how long the first bit takes depends on
+// where the divider was when the transfer started
+// Transfers using the external clock only progress when the peer calls
+// ClockIn, since the peer provides the clock
+func (gbserial *GBSerial) Clock() {
+	if !gbserial.transferring() || gbserial.sc&scInternalClock == 0 {
+		return
+	}
+
+	// Shift out the top bit. We don't know the peer's byte until the
+	// transfer is complete, so shift in 1s until then like an open line
+	gbserial.sb = gbserial.sb<<1 | 1
+	gbserial.bits++
+
+	if gbserial.bits == 8 {
+		in := byte(0xFF)
+		if gbserial.Peer != nil {
+			in = gbserial.Peer.Exchange(gbserial.out)
+		}
+		gbserial.complete(in)
+	}
+}
+
+// ClockIn is called by a peer providing the clock for a transfer
+// If the GB is waiting on an externally clocked transfer, it completes with
+// in as the received byte. Returns the byte the GB sent, and whether a
+// transfer took place
+// When nothing is waiting, the peer receives nothing, like on hardware
+func (gbserial *GBSerial) ClockIn(in byte) (byte, bool) {
+	if !gbserial.transferring() || gbserial.sc&scInternalClock != 0 {
+		return 0xFF, false
+	}
+
+	out := gbserial.sb
+	gbserial.complete(in)
+
+	return out, true
+}
+
+// complete finishes a transfer, storing the received byte and requesting
+// a serial interrupt
+func (gbserial *GBSerial) complete(in byte) {
+	gbserial.sb = in
+	gbserial.sc &^= scTransfer
+	gbserial.bits = 0
+
+	GbInterrupts.Request(interrupts.Serial)
+}
@@ -0,0 +1,117 @@
+package serial
+
+import (
+	"testing"
+
+	"../interrupts"
+)
+
+// linkPeer connects a GB using the internal clock to another GB waiting on
+// an externally clocked transfer, like a link cable between the two
+type linkPeer struct {
+	other *GBSerial
+}
+
+// Exchange clocks the other GB's transfer and returns the byte it sent
+func (link *linkPeer) Exchange(out byte) byte {
+	in, _ := link.other.ClockIn(out)
+	return in
+}
+
+// newTestSerial returns a serial port with no transfer in progress, and
+// clears any requested interrupts
+func newTestSerial() *GBSerial {
+	GbInterrupts = new(interrupts.GBInterrupts)
+
+	gbserial := new(GBSerial)
+	gbserial.InitSerial()
+
+	return gbserial
+}
+
+// serialRequested returns true if the serial interrupt has been requested
+func serialRequested() bool {
+	return GbInterrupts.ReadIF()&(1<<interrupts.Serial) != 0
+}
+
+// clockTransfer clocks gbserial 7 times and checks the transfer hasn't
+// finished yet, then clocks the 8th bit
+func clockTransfer(t *testing.T, gbserial *GBSerial) {
+	for bit := 0; bit < 7; bit++ {
+		gbserial.Clock()
+		if serialRequested() {
+			t.Fatalf("serial interrupt requested after %d bits", bit+1)
+		}
+		if gbserial.ReadSC()&scTransfer == 0 {
+			t.Fatalf("transfer finished after %d bits", bit+1)
+		}
+	}
+
+	gbserial.Clock()
+}
+
+func TestTransferBetweenPeers(t *testing.T) {
+	master := newTestSerial()
+	slave := new(GBSerial)
+	slave.InitSerial()
+	master.Peer = &linkPeer{other: slave}
+
+	slave.WriteSB(0x5A)
+	slave.WriteSC(scTransfer)
+	master.WriteSB(0xC3)
+	master.WriteSC(scTransfer | scInternalClock)
+
+	// The slave doesn't shift anything on its own
+	slave.Clock()
+	if slave.ReadSB() != 0x5A {
+		t.Fatalf("slave SB changed to $%02X without being clocked", slave.ReadSB())
+	}
+
+	clockTransfer(t, master)
+
+	if !serialRequested() {
+		t.Errorf("serial interrupt not requested after 8 bits")
+	}
+	if got := master.ReadSB(); got != 0x5A {
+		t.Errorf("master received $%02X, expected $5A", got)
+	}
+	if got := slave.ReadSB(); got != 0xC3 {
+		t.Errorf("slave received $%02X, expected $C3", got)
+	}
+	if master.ReadSC()&scTransfer != 0 || slave.ReadSC()&scTransfer != 0 {
+		t.Errorf("transfer still in progress, SC is $%02X and $%02X", master.ReadSC(), slave.ReadSC())
+	}
+}
+
+func TestTransferWithoutPeer(t *testing.T) {
+	gbserial := newTestSerial()
+	gbserial.WriteSB(0x42)
+	gbserial.WriteSC(scTransfer | scInternalClock)
+
+	clockTransfer(t, gbserial)
+
+	if !serialRequested() {
+		t.Errorf("serial interrupt not requested after 8 bits")
+	}
+	if got := gbserial.ReadSB(); got != 0xFF {
+		t.Errorf("received $%02X with nothing connected, expected $FF", got)
+	}
+}
+
+func TestExternalClockWithoutPeer(t *testing.T) {
+	gbserial := newTestSerial()
+	gbserial.WriteSB(0x42)
+	gbserial.WriteSC(scTransfer)
+
+	// Nothing provides the clock, so the transfer never finishes
+	for i := 0; i < 16; i++ {
+		gbserial.Clock()
+	}
+
+	if serialRequested() {
+		t.Errorf("serial interrupt requested without an external clock")
+	}
+	if got := gbserial.ReadSB(); got != 0x42 {
+		t.Errorf("SB is $%02X without an external clock, expected $42", got)
+	}
+}
@@ -4,18 +4,20 @@
 // Realized timer implementation was necessary when Tetris would play correctly,
 // but only would get square tetromino. This is because it uses the divider
 // timer register to get a "random" block based on its value
+// Reference: http://gbdev.gg8.se/wiki/articles/Timer_Obscure_Behaviour
 package timer
 
 import (
+	"../interrupts"
 	"../mmu"
 )
 
 // GBTimer keeps time separately from LC
 // Allows us to only modify memory values when we know we have to
 type GBTimer struct {
-	main int
-	sub  int
-	div  int
+	// Internal counter incremented every cycle
+	// The divider register (0xFF04) is its upper 8 bits
+	counter uint16
 }
 
 // GbMMU injection from main.go
@@ -23,62 +25,81 @@ type GBTimer struct {
 // May refactor to instead return values and write to memory in mmu.go
 var GbMMU *mmu.GBMMU
 
-// Increment increments the timer and writes to the divider
-// at a rate of 1/16th the base clock speed
+// GbInterrupts injection from main.go
+// Used to request the timer interrupt when TIMA overflows
+var GbInterrupts *interrupts.GBInterrupts
+
+// ClockSerial injection from main.go
+// Called on each falling edge of serialBit, which shifts the serial port
+var ClockSerial func()
+
+// Bit of the internal counter that clocks TIMA, for each TAC clock select
+// TIMA increments when the selected bit goes from 1 to 0
+// 4096Hz, 262144Hz, 65536Hz and 16384Hz
+var timaBits = [4]uint{9, 3, 5, 7}
+
+// Bit of the internal counter that clocks serial transfers using the
+// internal clock, when it goes from 1 to 0. This is 8192Hz
+const serialBit = 8
+
+// Increment runs the timer for the number of cycles executed
 func (gbtimer *GBTimer) Increment(cycles int) {
-	// Increment by cycles of last opcode
-	gbtimer.sub += cycles
-
-	if gbtimer.sub >= 4 {
-		gbtimer.main++
-		gbtimer.sub -= 4
-
-		gbtimer.div++
-		if gbtimer.div == 16 {
-			GbMMU.Memory[0xFF04] = (GbMMU.Memory[0xFF04] + 1) & 255
-			gbtimer.div = 0
-		}
+	for i := 0; i < cycles; i++ {
+		prevCounter := gbtimer.counter
+		gbtimer.counter++
+		gbtimer.checkStep(prevCounter)
+		gbtimer.checkSerial(prevCounter)
 	}
 
-	// Check if we need to step the timer depending on selected
-	// input clock speed
-	gbtimer.checkStep()
+	GbMMU.Memory[0xFF04] = byte(gbtimer.counter >> 8)
+}
+
+// ResetDivider clears the internal counter, which happens when the game
+// writes any value to the divider register
+func (gbtimer *GBTimer) ResetDivider() {
+	prevCounter := gbtimer.counter
+	gbtimer.counter = 0
+	GbMMU.Memory[0xFF04] = 0
+
+	// Clearing the counter can cause a falling edge on the selected bit,
+	// and on the serial clock's
+	gbtimer.checkStep(prevCounter)
+	gbtimer.checkSerial(prevCounter)
+}
+
+// checkSerial clocks the serial port if serialBit of the counter fell since
+// prevCounter
+func (gbtimer *GBTimer) checkSerial(prevCounter uint16) {
+	if prevCounter&(1<<serialBit) != 0 && gbtimer.counter&(1<<serialBit) == 0 {
+		ClockSerial()
+	}
 }
 
 // checkStep checks the Timer Control register
-// steps the CPU if the timer > the selected clock speed
+// steps the timer if the selected bit of the counter fell since prevCounter
 // This allows for games to run at different speeds like Tetris blocks falling
-func (gbtimer *GBTimer) checkStep() {
-	selectedClock := 0
-	if GbMMU.Memory[0xFF07]&4 != 0 {
-		switch GbMMU.Memory[0xFF07] & 3 {
-		case 0:
-			selectedClock = 64
-		case 1:
-			selectedClock = 1
-		case 2:
-			selectedClock = 4
-		case 3:
-			selectedClock = 16
-		}
-
-		if gbtimer.main >= selectedClock {
-			gbtimer.step()
-		}
+func (gbtimer *GBTimer) checkStep(prevCounter uint16) {
+	tac := GbMMU.Memory[0xFF07]
+	if tac&4 == 0 {
+		return
+	}
+
+	bit := timaBits[tac&3]
+	if prevCounter&(1<<bit) != 0 && gbtimer.counter&(1<<bit) == 0 {
+		gbtimer.step()
 	}
 }
 
 // Step increments the counter, sets it to modulo if it overflows,
 // and sets the bit to call a timer interrupt if overflow happened
 func (gbtimer *GBTimer) step() {
-	gbtimer.main = 0
 	prevCount := GbMMU.Memory[0xFF05]
 	GbMMU.Memory[0xFF05]++
 
 	if GbMMU.Memory[0xFF05] < prevCount {
 		GbMMU.Memory[0xFF05] = GbMMU.Memory[0xFF06]
 
-		// Set timer interrupt bit
-		GbMMU.Memory[0xFF0F] |= (1 << 2)
+		// Request timer interrupt
+		GbInterrupts.Request(interrupts.Timer)
 	}
 }
@@ -0,0 +1,66 @@
+package timer
+
+import (
+	"testing"
+
+	"../interrupts"
+	"../mmu"
+)
+
+// newTestTimer returns a timer with its counter at 0, counting how many
+// times it clocks the serial port in clocks
+func newTestTimer(clocks *int) *GBTimer {
+	GbMMU = new(mmu.GBMMU)
+	GbInterrupts = new(interrupts.GBInterrupts)
+	ClockSerial = func() {
+		*clocks++
+	}
+
+	return new(GBTimer)
+}
+
+func TestSerialClock(t *testing.T) {
+	clocks := 0
+	gbtimer := newTestTimer(&clocks)
+
+	// Bit 8 first falls when the counter reaches 512, then every 512 cycles
+	gbtimer.Increment(511)
+	if clocks != 0 {
+		t.Errorf("serial clocked %d times in the first 511 cycles", clocks)
+	}
+
+	gbtimer.Increment(1)
+	if clocks != 1 {
+		t.Errorf("serial clocked %d times after 512 cycles, expected 1", clocks)
+	}
+
+	gbtimer.Increment(512 * 7)
+	if clocks != 8 {
+		t.Errorf("serial clocked %d times after 4096 cycles, expected 8", clocks)
+	}
+}
+
+func TestSerialClockOnDividerReset(t *testing.T) {
+	clocks := 0
+	gbtimer := newTestTimer(&clocks)
+
+	// Resetting with bit 8 clear doesn't clock
+	gbtimer.Increment(100)
+	gbtimer.ResetDivider()
+	if clocks != 0 {
+		t.Errorf("serial clocked %d times resetting with bit 8 clear", clocks)
+	}
+
+	// Resetting with bit 8 set is a falling edge
+	gbtimer.Increment(300)
+	gbtimer.ResetDivider()
+	if clocks != 1 {
+		t.Errorf("serial clocked %d times resetting with bit 8 set, expected 1", clocks)
+	}
+
+	// The next edge is a full 512 cycles after the reset
+	gbtimer.Increment(511)
+	if clocks != 1 {
+		t.Errorf("serial clocked again %d cycles after the reset", 511)
+	}
+}
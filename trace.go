@@ -0,0 +1,90 @@
+package main
+
+// Trace mode logs every executed instruction in Gameboy Doctor's format
+// See cpu/trace.go
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"./cpu"
+	"github.com/hajimehoshi/ebiten"
+)
+
+// File the trace is written to, nil when writing to stdout
+var traceFile *os.File
+
+// Whether the trace toggle key was down last frame, so holding it down only
+// toggles once
+var traceKeyDown bool
+
+// startTrace creates a tracer from the command line flags, if -trace is set
+func startTrace() error {
+	if *traceFlag == "" {
+		return nil
+	}
+
+	from, to := uint16(0x0000), uint16(0xFFFF)
+	if *traceRangeFlag != "" {
+		bounds := strings.SplitN(*traceRangeFlag, "-", 2)
+		if len(bounds) != 2 {
+			return fmt.Errorf("trace: invalid range %q, expected START-END", *traceRangeFlag)
+		}
+
+		var err error
+		from, err = parseAddr(bounds[0])
+		if err != nil {
+			return fmt.Errorf("trace: %s", err)
+		}
+		to, err = parseAddr(bounds[1])
+		if err != nil {
+			return fmt.Errorf("trace: %s", err)
+		}
+	}
+
+	out := os.Stdout
+	if *traceFlag != "-" {
+		file, err := os.Create(*traceFlag)
+		if err != nil {
+			return fmt.Errorf("trace: %s", err)
+		}
+		traceFile = file
+		out = file
+	}
+
+	GbCPU.Tracer = cpu.NewTracer(out, *traceRingFlag)
+	GbCPU.Tracer.Enabled = !*tracePausedFlag
+	GbCPU.Tracer.From = from
+	GbCPU.Tracer.To = to
+	GbCPU.Tracer.Bank = *traceBankFlag
+
+	return nil
+}
+
+// toggleTrace pauses or resumes tracing when T is pressed
+func toggleTrace() {
+	pressed := ebiten.IsKeyPressed(ebiten.KeyT)
+	if pressed && !traceKeyDown && GbCPU.Tracer != nil {
+		GbCPU.Tracer.Enabled = !GbCPU.Tracer.Enabled
+	}
+	traceKeyDown = pressed
+}
+
+// stopTrace writes out anything left in the trace and closes its file
+func stopTrace() error {
+	if GbCPU.Tracer == nil {
+		return nil
+	}
+
+	err := GbCPU.Tracer.Flush()
+	if err != nil {
+		return fmt.Errorf("trace: %s", err)
+	}
+
+	if traceFile != nil {
+		return traceFile.Close()
+	}
+
+	return nil
+}